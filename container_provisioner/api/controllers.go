@@ -10,6 +10,7 @@ import (
 
 	"github.com/algo7/TripAdvisor-Review-Scraper/container_provisioner/scrape"
 	"github.com/algo7/TripAdvisor-Review-Scraper/container_provisioner/utils"
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor/locations"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -34,9 +35,21 @@ func (h *Handler) getMain(c *fiber.Ctx) error {
 	return c.Render("main", fiber.Map{
 		"Title":             "Algo7 TripAdvisor Scraper",
 		"RunningContainers": len(runningContainers),
+		"LocationTypes":     locationTypeOptions(),
 	})
 }
 
+// locationTypeOptions returns the descriptors of the location types the scraper supports, in registration order
+func locationTypeOptions() []*locations.Descriptor {
+	var options []*locations.Descriptor
+	for _, locationType := range locations.Types() {
+		if descriptor, err := locations.Lookup(locationType); err == nil {
+			options = append(options, descriptor)
+		}
+	}
+	return options
+}
+
 // postProvision is the handler for the form submission
 func (h *Handler) postProvision(c *fiber.Ctx) error {
 
@@ -49,17 +62,8 @@ func (h *Handler) postProvision(c *fiber.Ctx) error {
 	// Get the scrape mode from the form
 	scrapeMode := c.FormValue("scrape_option")
 
-	// Define valid scrape modes
-	validChoices := map[string]bool{
-		"HOTEL":   true,
-		"RESTO":   true,
-		"AIRLINE": true,
-	}
-
-	_, exists := validChoices[scrapeMode]
-
-	// Validate the scrape mode
-	if !exists {
+	// Validate the scrape mode against the location types supported by the scraper
+	if _, err := locations.ParseType(scrapeMode); err != nil {
 		return c.Render("submission", fiber.Map{
 			"Title":      "Algo7 TripAdvisor Scraper",
 			"Message1":   "Invalid Scrape Target",
@@ -17,6 +17,9 @@ import (
 const (
 	containerImage = "ghcr.io/algo7/tripadvisor-review-scraper/scraper:latest"
 	// containerImage = "scraper:latest"
+
+	// ScraperFileType is the file type the scraper containers write their output in
+	ScraperFileType = "csv"
 )
 
 var (
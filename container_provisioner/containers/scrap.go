@@ -23,6 +23,7 @@ func (c *ContainerManager) ContainerConfigGenerator(
 		Env: []string{
 			fmt.Sprintf("LOCATION_URL=%s", locationURL),
 			fmt.Sprintf("PROXY_HOST=%s", proxyAddress),
+			fmt.Sprintf("FILETYPE=%s", ScraperFileType),
 		},
 		Tty: true,
 	}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"path/filepath"
 
 	"github.com/algo7/TripAdvisor-Review-Scraper/container_provisioner/containers"
 	"github.com/algo7/TripAdvisor-Review-Scraper/container_provisioner/database"
 	"github.com/algo7/TripAdvisor-Review-Scraper/container_provisioner/storage"
 	"github.com/algo7/TripAdvisor-Review-Scraper/container_provisioner/utils"
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/exitcode"
 	"github.com/docker/docker/api/types/container"
 )
 
@@ -54,6 +56,13 @@ func (s *Scraper) Scrape(uploadIdentifier string, targetName string, containerID
 		}
 
 	case status := <-statusCh:
+		// If the scraper was interrupted, upload the partial output it flushed before exiting
+		if status.StatusCode == exitcode.Interrupted {
+			log.Printf("container %s was interrupted, uploading its partial output", containerID)
+			targetName += "-partial"
+			break
+		}
+
 		// If the container exited with non-zero status code, remove the container and return an error
 		if status.StatusCode != 0 {
 			log.Printf("container %s exited with status code %d", containerID, status.StatusCode)
@@ -66,14 +75,14 @@ func (s *Scraper) Scrape(uploadIdentifier string, targetName string, containerID
 		}
 	}
 
-	// The file path in the container
-	filePathInContainer := "reviews.csv"
+	// The file path in the container, named after the file type the scraper was configured with
+	filePathInContainer := "reviews." + containers.ScraperFileType
 
 	// Get the file size in the container
 	// 	// Log the file size in the container
 	containerFileInfo, err := s.CM.Client.ContainerStatPath(context.Background(), containerID, filePathInContainer)
 	if err != nil {
-		return fmt.Errorf("error getting csv file size in container: %w", err)
+		return fmt.Errorf("error getting output file size in container: %w", err)
 	} else {
 		log.Printf("file size in container: %d bytes", containerFileInfo.Size)
 	}
@@ -88,12 +97,12 @@ func (s *Scraper) Scrape(uploadIdentifier string, targetName string, containerID
 	fileSuffix := utils.GenerateUUID()
 
 	// Write the file to the host
-	exportedFileName, err := utils.WriteToFileFromTarStream(targetName, fileSuffix, fileReader)
+	exportedFileName, err := utils.WriteToFileFromTarStream(targetName, fileSuffix, filepath.Ext(filePathInContainer), fileReader)
 	if err != nil {
 		return fmt.Errorf("fail to write file to host: %w", err)
 	}
 
-	// Read the exported csv file
+	// Read the exported file
 	file, err := utils.ReadFromFile(exportedFileName)
 	if err != nil {
 		return fmt.Errorf("fail to read exported file %s: %w", exportedFileName, err)
@@ -6,21 +6,14 @@ import (
 	"io"
 	"os"
 	"regexp"
-	"strings"
 	"time"
 
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor/locations"
 	"github.com/google/uuid"
 )
 
-// Compile the regular expression and store it in a package-level variable
-var (
-	tripAdvisorHotelURLRegexp   = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Hotel_Review-g\d{6,10}-d\d{1,10}-Reviews-[\w-]{1,255}\.html$`)
-	tripAdvisorRestaurantRegexp = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Restaurant_Review-g\d{6,10}-d\d{1,10}-Reviews-[\w-]{1,255}\.html$`)
-	tripAdvisorAirlineRegexp    = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Airline_Review-d\d{6,10}-Reviews-[\w-]{1,255}$`)
-)
-
-// WriteToFileFromTarStream writes a file to disk
-func WriteToFileFromTarStream(fileName string, fileSuffix string, tarF io.ReadCloser) (string, error) {
+// WriteToFileFromTarStream writes a file to disk, named after fileName and fileSuffix with the given extension, such as .csv
+func WriteToFileFromTarStream(fileName string, fileSuffix string, extension string, tarF io.ReadCloser) (string, error) {
 
 	// Untar the file
 	// Note: This is not a generic untar function. It only works for a single file
@@ -52,7 +45,7 @@ func WriteToFileFromTarStream(fileName string, fileSuffix string, tarF io.ReadCl
 		return "", fmt.Errorf("fail to read the tar file: %w", err)
 	}
 
-	fileNameToWrite := fileName + "-" + fileSuffix + ".csv"
+	fileNameToWrite := fileName + "-" + fileSuffix + extension
 
 	// Create the file
 	out, err := os.Create(fileNameToWrite)
@@ -75,7 +68,7 @@ func WriteToFileFromTarStream(fileName string, fileSuffix string, tarF io.ReadCl
 func ReadFromFile(fileName string) (*os.File, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("fail to read extracted csv file: %w", err)
+		return nil, fmt.Errorf("fail to read extracted file: %w", err)
 	}
 
 	return file, nil
@@ -83,32 +76,18 @@ func ReadFromFile(fileName string) (*os.File, error) {
 
 // GetLocationNameFromURL get the scrape target name from the given URL
 func GetLocationNameFromURL(url string, scrapOption string) string {
-
-	// Split the url by "-"
-	splitURL := strings.Split(url, "-")
-
-	switch scrapOption {
-	case "HOTEL", "RESTO":
-		return splitURL[4]
-	case "AIRLINE":
-		return strings.Join(splitURL[3:], "_")
-	default:
+	location, err := locations.Parse(url, locations.Type(scrapOption))
+	if err != nil {
 		return ""
 	}
+	return location.Name
 }
 
-// ValidateTripAdvisorURL validates the TripAdvisor URLs
+// ValidateTripAdvisorURL validates the TripAdvisor URLs of the location type the same way the scraper parses them,
+// so that any URL the scraper accepts, such as a later review page or a mobile URL, is accepted
 func ValidateTripAdvisorURL(url string, scrapOption string) bool {
-	switch scrapOption {
-	case "HOTEL":
-		return tripAdvisorHotelURLRegexp.MatchString(url)
-	case "RESTO":
-		return tripAdvisorRestaurantRegexp.MatchString(url)
-	case "AIRLINE":
-		return tripAdvisorAirlineRegexp.MatchString(url)
-	default:
-		return false
-	}
+	_, err := locations.Parse(url, locations.Type(scrapOption))
+	return err == nil
 }
 
 // ValidateEmailAddress validates the EHL email address
@@ -0,0 +1,178 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of scraping a single location of a batch
+type Result struct {
+	URL                  string         `json:"url"`
+	LocationID           uint32         `json:"locationId,omitempty"`
+	LocationName         string         `json:"locationName,omitempty"`
+	OutputFile           string         `json:"outputFile,omitempty"`
+	Success              bool           `json:"success"`
+	Partial              bool           `json:"partial,omitempty"`
+	ReviewCount          int            `json:"reviewCount"`
+	LanguageReviewCounts map[string]int `json:"languageReviewCounts,omitempty"`
+	ResponseRate         float64        `json:"responseRate"`
+	MedianResponseDays   *float64       `json:"medianResponseDays,omitempty"`
+	Error                string         `json:"error,omitempty"`
+	DurationSeconds      float64        `json:"durationSeconds"`
+}
+
+// Manifest summarizes a batch run
+type Manifest struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Total      int       `json:"total"`
+	Succeeded  int       `json:"succeeded"`
+	Failed     int       `json:"failed"`
+	Results    []Result  `json:"results"`
+}
+
+// ReadURLs reads the location URLs of a batch from a file.
+// Plain text files hold one URL per line, with empty lines and lines starting with # ignored.
+// CSV files (detected by their .csv extension) may hold the URL in any column, and rows without a URL, such as headers, are ignored.
+// Duplicate URLs are only returned once.
+func ReadURLs(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening URL file: %w", err)
+	}
+	defer file.Close()
+
+	var urls []string
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		urls, err = readCSVURLs(file)
+	} else {
+		urls, err = readTextURLs(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading URL file %s: %w", path, err)
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no URLs found in %s", path)
+	}
+
+	return urls, nil
+}
+
+// readTextURLs reads one URL per line
+func readTextURLs(r io.Reader) ([]string, error) {
+	var urls []string
+	seen := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = appendUnique(urls, seen, line)
+	}
+
+	return urls, scanner.Err()
+}
+
+// readCSVURLs reads the first URL of every CSV row
+func readCSVURLs(r io.Reader) ([]string, error) {
+	var urls []string
+	seen := make(map[string]struct{})
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return urls, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, field := range record {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+				urls = appendUnique(urls, seen, field)
+				break
+			}
+		}
+	}
+}
+
+// appendUnique appends the URL unless it was already seen
+func appendUnique(urls []string, seen map[string]struct{}, url string) []string {
+	if _, ok := seen[url]; ok {
+		return urls
+	}
+	seen[url] = struct{}{}
+	return append(urls, url)
+}
+
+// Run scrapes every URL with up to workers locations in parallel and returns the manifest of the run.
+// The results of the manifest are in the same order as the URLs.
+func Run(urls []string, workers int, scrape func(url string) Result) *Manifest {
+	manifest := &Manifest{
+		StartedAt: time.Now().UTC(),
+		Total:     len(urls),
+		Results:   make([]Result, len(urls)),
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			for i := range jobs {
+				start := time.Now()
+				result := scrape(urls[i])
+				result.URL = urls[i]
+				result.DurationSeconds = time.Since(start).Seconds()
+				manifest.Results[i] = result
+			}
+		})
+	}
+
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range manifest.Results {
+		if result.Success {
+			manifest.Succeeded++
+		} else {
+			manifest.Failed++
+		}
+	}
+	manifest.FinishedAt = time.Now().UTC()
+
+	return manifest
+}
+
+// WriteFile writes the manifest to the given path as JSON
+func (m *Manifest) WriteFile(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+
+	return nil
+}
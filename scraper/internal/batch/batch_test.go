@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadURLs(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		content     string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "text file with comments and blank lines",
+			fileName: "urls.txt",
+			content: "# Hotels\n" +
+				"https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace.html\n" +
+				"\n" +
+				"  https://www.tripadvisor.com/Restaurant_Review-g187147-d1751525-Reviews-Cafe_Le_Dome.html  \n",
+			expected: []string{
+				"https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace.html",
+				"https://www.tripadvisor.com/Restaurant_Review-g187147-d1751525-Reviews-Cafe_Le_Dome.html",
+			},
+		},
+		{
+			name:     "csv file with a header",
+			fileName: "urls.csv",
+			content: "name,url\n" +
+				"Beau Rivage,https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace.html\n" +
+				"Lufthansa,https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa\n",
+			expected: []string{
+				"https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace.html",
+				"https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			},
+		},
+		{
+			name:     "duplicates are removed",
+			fileName: "urls.txt",
+			content: "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa\n" +
+				"https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa\n",
+			expected: []string{
+				"https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			},
+		},
+		{
+			name:        "file without URLs",
+			fileName:    "urls.txt",
+			content:     "# nothing to scrape\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			urls, err := ReadURLs(path)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, urls)
+		})
+	}
+}
+
+func TestReadURLsMissingFile(t *testing.T) {
+	_, err := ReadURLs(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	urls := []string{"https://a", "https://b", "https://c", "https://d"}
+
+	var calls atomic.Int32
+	manifest := Run(urls, 2, func(url string) Result {
+		calls.Add(1)
+		if url == "https://c" {
+			return Result{Error: "blocked"}
+		}
+		return Result{Success: true, ReviewCount: 10}
+	})
+
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, 4, manifest.Total)
+	assert.Equal(t, 3, manifest.Succeeded)
+	assert.Equal(t, 1, manifest.Failed)
+	for i, result := range manifest.Results {
+		assert.Equal(t, urls[i], result.URL, "results keep the order of the URLs")
+	}
+	assert.Equal(t, "blocked", manifest.Results[2].Error)
+	assert.False(t, manifest.FinishedAt.Before(manifest.StartedAt))
+}
+
+func TestManifestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	manifest := &Manifest{
+		Total:     1,
+		Succeeded: 1,
+		Results:   []Result{{URL: "https://a", Success: true, ReviewCount: 3}},
+	}
+
+	assert.NoError(t, manifest.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var decoded Manifest
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, manifest.Results, decoded.Results)
+}
@@ -3,27 +3,83 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor"
 )
 
 // Config is a struct that represents the configuration for the scraper
 type Config struct {
-	LocationURL string
-	Languages   []string
-	FileType    string
-	ProxyHost   string
+	LocationURL               string
+	LocationType              tripadvisor.LocationType
+	Languages                 []string
+	PerLanguage               bool
+	FileType                  string
+	ProxyHost                 string
+	CheckpointFile            string
+	RetryAttempts             int
+	Concurrency               int
+	RequestsPerSecond         float64
+	Since                     string
+	SinceFile                 string
+	URLFile                   string
+	BatchConcurrency          int
+	RegionalEndpoint          bool
+	NDJSONMetadata            bool
+	CSVColumns                []string
+	CSVDelimiter              rune
+	CSVQuoteAll               bool
+	CSVBOM                    bool
+	DisableMachineTranslation bool
+	OriginalText              bool
+	Filters                   tripadvisor.ReviewFilters
+	SortBy                    string
+	PhotosPerReview           uint32
+	DownloadPhotos            bool
+	PhotoSize                 tripadvisor.PhotoSize
+	Debug                     bool
 }
 
 // NewConfig is a function that returns a new Config struct
-// Returns an error if the LOCATION_URL is not set
+// Returns an error if neither the LOCATION_URL nor the URL_FILE is set
 func NewConfig() (*Config, error) {
 	// Default languages
 	defaultLanguages := []string{"en"}
 
-	// Get location URL
+	// Default checkpoint file
+	defaultCheckpointFile := "checkpoint.json"
+
+	// Default number of attempts per request
+	defaultRetryAttempts := 5
+
+	// Default concurrency settings. A single worker keeps the sequential behaviour.
+	defaultConcurrency := 1
+	defaultRequestsPerSecond := 1.0
+
+	// Default number of locations scraped in parallel in batch mode
+	defaultBatchConcurrency := 1
+
+	// Get location URL, or the file listing the location URLs in batch mode
 	locationURL := os.Getenv("LOCATION_URL")
-	if locationURL == "" {
-		return nil, fmt.Errorf("LOCATION_URL not set")
+	urlFile := os.Getenv("URL_FILE")
+	if locationURL == "" && urlFile == "" {
+		return nil, fmt.Errorf("LOCATION_URL not set. Set LOCATION_URL or URL_FILE")
+	}
+	if locationURL != "" && urlFile != "" {
+		return nil, fmt.Errorf("LOCATION_URL and URL_FILE are mutually exclusive")
+	}
+
+	// Get the location type, required when locations are given by ID instead of URL
+	var locationType tripadvisor.LocationType
+	if envLocationType := os.Getenv("LOCATION_TYPE"); envLocationType != "" {
+		parsed, err := tripadvisor.ParseLocationType(envLocationType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOCATION_TYPE. Use one of %v", tripadvisor.LocationTypes())
+		}
+		locationType = parsed
 	}
 
 	// Get languages
@@ -32,23 +88,293 @@ func NewConfig() (*Config, error) {
 		languages = strings.Split(envLang, "|")
 	}
 
+	// Get whether each language is scraped separately
+	perLanguage := false
+	if envPerLanguage := os.Getenv("PER_LANGUAGE"); envPerLanguage != "" {
+		separately, err := strconv.ParseBool(envPerLanguage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PER_LANGUAGE. Use true or false")
+		}
+		perLanguage = separately
+	}
+
+	// Get whether reviews written in another language are machine translated
+	disableMachineTranslation := false
+	if envMachineTranslation := os.Getenv("MACHINE_TRANSLATION"); envMachineTranslation != "" {
+		translate, err := strconv.ParseBool(envMachineTranslation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MACHINE_TRANSLATION. Use true or false")
+		}
+		disableMachineTranslation = !translate
+	}
+
+	// Get whether machine translated reviews are fetched a second time as written
+	originalText := false
+	if envOriginalText := os.Getenv("ORIGINAL_TEXT"); envOriginalText != "" {
+		fetch, err := strconv.ParseBool(envOriginalText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ORIGINAL_TEXT. Use true or false")
+		}
+		originalText = fetch
+	}
+
 	// Get file type
 	fileType := strings.ToLower(os.Getenv("FILETYPE"))
 	if fileType == "" {
 		fileType = "csv"
 	}
 
-	if fileType != "csv" && fileType != "json" {
-		return nil, fmt.Errorf("invalid file type. Use csv or json")
+	switch fileType {
+	case "csv", "json", "ndjson", "parquet", "sqlite", "xlsx":
+	default:
+		return nil, fmt.Errorf("invalid file type. Use csv, json, ndjson, parquet, sqlite or xlsx")
+	}
+
+	// Get whether the ndjson output ends with a metadata line
+	ndjsonMetadata := false
+	if envNDJSONMetadata := os.Getenv("NDJSON_METADATA"); envNDJSONMetadata != "" {
+		withMetadata, err := strconv.ParseBool(envNDJSONMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NDJSON_METADATA. Use true or false")
+		}
+		ndjsonMetadata = withMetadata
+	}
+
+	// Get the columns of the csv output
+	var csvColumns []string
+	if envCSVColumns := os.Getenv("CSV_COLUMNS"); envCSVColumns != "" {
+		for _, column := range strings.Split(envCSVColumns, "|") {
+			if column = strings.TrimSpace(column); column != "" {
+				csvColumns = append(csvColumns, column)
+			}
+		}
+		if err := tripadvisor.ValidateCSVColumns(csvColumns); err != nil {
+			return nil, fmt.Errorf("invalid CSV_COLUMNS: %w", err)
+		}
+	}
+
+	// Get the delimiter of the csv output. Zero keeps the default comma.
+	var csvDelimiter rune
+	if envCSVDelimiter := os.Getenv("CSV_DELIMITER"); envCSVDelimiter != "" {
+		if envCSVDelimiter == "tab" || envCSVDelimiter == `\t` {
+			envCSVDelimiter = "\t"
+		}
+		delimiter, size := utf8.DecodeRuneInString(envCSVDelimiter)
+		if size != len(envCSVDelimiter) || delimiter == utf8.RuneError || strings.ContainsRune("\"\r\n", delimiter) {
+			return nil, fmt.Errorf("invalid CSV_DELIMITER. Use a single character other than a quote or a line break")
+		}
+		csvDelimiter = delimiter
+	}
+
+	// Get whether every field of the csv output is quoted
+	csvQuoteAll := false
+	switch strings.ToLower(os.Getenv("CSV_QUOTE")) {
+	case "", "minimal":
+	case "all":
+		csvQuoteAll = true
+	default:
+		return nil, fmt.Errorf("invalid CSV_QUOTE. Use minimal or all")
+	}
+
+	// Get whether the csv output starts with a UTF-8 byte order mark
+	csvBOM := false
+	if envCSVBOM := os.Getenv("CSV_BOM"); envCSVBOM != "" {
+		bom, err := strconv.ParseBool(envCSVBOM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV_BOM. Use true or false")
+		}
+		csvBOM = bom
+	}
+
+	// Get the number of photos requested with each review, left to the default when unset
+	var photosPerReview uint32
+	if envPhotosPerReview := os.Getenv("PHOTOS_PER_REVIEW"); envPhotosPerReview != "" {
+		photos, err := strconv.ParseUint(envPhotosPerReview, 10, 32)
+		if err != nil || photos < 1 {
+			return nil, fmt.Errorf("invalid PHOTOS_PER_REVIEW. Use a positive integer")
+		}
+		photosPerReview = uint32(photos)
+	}
+
+	// Get whether the photos of the reviews are downloaded, and at which size
+	downloadPhotos := false
+	if envDownloadPhotos := os.Getenv("DOWNLOAD_PHOTOS"); envDownloadPhotos != "" {
+		download, err := strconv.ParseBool(envDownloadPhotos)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DOWNLOAD_PHOTOS. Use true or false")
+		}
+		downloadPhotos = download
+	}
+	photoSize, err := tripadvisor.ParsePhotoSize(os.Getenv("PHOTO_SIZE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PHOTO_SIZE. Use original or a size such as 800x600")
 	}
 
 	// Get proxy host
 	proxyHost := os.Getenv("PROXY_HOST")
 
+	// Get checkpoint file
+	checkpointFile := defaultCheckpointFile
+	if envCheckpointFile := os.Getenv("CHECKPOINT_FILE"); envCheckpointFile != "" {
+		checkpointFile = envCheckpointFile
+	}
+
+	// Get retry attempts
+	retryAttempts := defaultRetryAttempts
+	if envRetryAttempts := os.Getenv("RETRY_ATTEMPTS"); envRetryAttempts != "" {
+		attempts, err := strconv.Atoi(envRetryAttempts)
+		if err != nil || attempts < 1 {
+			return nil, fmt.Errorf("invalid RETRY_ATTEMPTS. Use a positive integer")
+		}
+		retryAttempts = attempts
+	}
+
+	// Get the number of concurrent workers
+	concurrency := defaultConcurrency
+	if envConcurrency := os.Getenv("CONCURRENCY"); envConcurrency != "" {
+		workers, err := strconv.Atoi(envConcurrency)
+		if err != nil || workers < 1 {
+			return nil, fmt.Errorf("invalid CONCURRENCY. Use a positive integer")
+		}
+		concurrency = workers
+	}
+
+	// Get the request rate limit used in concurrent mode
+	requestsPerSecond := defaultRequestsPerSecond
+	if envRequestsPerSecond := os.Getenv("REQUESTS_PER_SECOND"); envRequestsPerSecond != "" {
+		rps, err := strconv.ParseFloat(envRequestsPerSecond, 64)
+		if err != nil || rps <= 0 {
+			return nil, fmt.Errorf("invalid REQUESTS_PER_SECOND. Use a positive number")
+		}
+		requestsPerSecond = rps
+	}
+
+	// Get the cutoff of the incremental mode, given either as a date or as the output of a previous scrape
+	since := os.Getenv("SINCE")
+	if since != "" {
+		if _, err := time.Parse("2006-01-02", since); err != nil {
+			return nil, fmt.Errorf("invalid SINCE. Use the YYYY-MM-DD format")
+		}
+	}
+	sinceFile := os.Getenv("SINCE_FILE")
+	if since != "" && sinceFile != "" {
+		return nil, fmt.Errorf("SINCE and SINCE_FILE are mutually exclusive")
+	}
+	if sinceFile != "" && urlFile != "" {
+		return nil, fmt.Errorf("SINCE_FILE is not supported in batch mode. Use SINCE instead")
+	}
+
+	// Get the filters applied by TripAdvisor on top of the languages
+	var filters tripadvisor.ReviewFilters
+	if envRatings := os.Getenv("RATINGS"); envRatings != "" {
+		ratings, err := parseIntList(envRatings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATINGS. Use ratings from 1 to 5 separated by |")
+		}
+		filters.Ratings = ratings
+	}
+	if envTripTypes := os.Getenv("TRIP_TYPES"); envTripTypes != "" {
+		filters.TripTypes = strings.Split(strings.ToUpper(envTripTypes), "|")
+	}
+	if envMonths := os.Getenv("MONTHS"); envMonths != "" {
+		months, err := parseIntList(envMonths)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONTHS. Use months from 1 to 12 separated by |")
+		}
+		filters.Months = months
+	}
+	if err := filters.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	// Get the order of the reviews. The incremental mode needs the newest reviews first.
+	var sortBy string
+	switch strings.ToLower(os.Getenv("SORT")) {
+	case "":
+	case "default":
+		sortBy = tripadvisor.SortByServerDetermined
+	case "recent":
+		sortBy = tripadvisor.SortByDate
+	case "helpful":
+		sortBy = tripadvisor.SortByHelpful
+	default:
+		return nil, fmt.Errorf("invalid SORT. Use default, recent or helpful")
+	}
+	if (since != "" || sinceFile != "") && sortBy != "" && sortBy != tripadvisor.SortByDate {
+		return nil, fmt.Errorf("SORT must be recent, or left unset, with SINCE or SINCE_FILE")
+	}
+
+	// Get the number of locations scraped in parallel in batch mode
+	batchConcurrency := defaultBatchConcurrency
+	if envBatchConcurrency := os.Getenv("BATCH_CONCURRENCY"); envBatchConcurrency != "" {
+		workers, err := strconv.Atoi(envBatchConcurrency)
+		if err != nil || workers < 1 {
+			return nil, fmt.Errorf("invalid BATCH_CONCURRENCY. Use a positive integer")
+		}
+		batchConcurrency = workers
+	}
+
+	// Get whether requests are sent to the regional domain of the location URL instead of www.tripadvisor.com
+	regionalEndpoint := false
+	if envRegionalEndpoint := os.Getenv("REGIONAL_ENDPOINT"); envRegionalEndpoint != "" {
+		regional, err := strconv.ParseBool(envRegionalEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REGIONAL_ENDPOINT. Use true or false")
+		}
+		regionalEndpoint = regional
+	}
+
+	// Get whether the raw responses are logged
+	debug := false
+	if envDebug := os.Getenv("DEBUG"); envDebug != "" {
+		enabled, err := strconv.ParseBool(envDebug)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DEBUG. Use true or false")
+		}
+		debug = enabled
+	}
+
 	return &Config{
-		LocationURL: locationURL,
-		Languages:   languages,
-		FileType:    fileType,
-		ProxyHost:   proxyHost,
+		LocationURL:               locationURL,
+		LocationType:              locationType,
+		Languages:                 languages,
+		PerLanguage:               perLanguage,
+		FileType:                  fileType,
+		ProxyHost:                 proxyHost,
+		CheckpointFile:            checkpointFile,
+		RetryAttempts:             retryAttempts,
+		Concurrency:               concurrency,
+		RequestsPerSecond:         requestsPerSecond,
+		Since:                     since,
+		SinceFile:                 sinceFile,
+		URLFile:                   urlFile,
+		BatchConcurrency:          batchConcurrency,
+		RegionalEndpoint:          regionalEndpoint,
+		NDJSONMetadata:            ndjsonMetadata,
+		CSVColumns:                csvColumns,
+		CSVDelimiter:              csvDelimiter,
+		CSVQuoteAll:               csvQuoteAll,
+		CSVBOM:                    csvBOM,
+		DisableMachineTranslation: disableMachineTranslation,
+		OriginalText:              originalText,
+		Filters:                   filters,
+		SortBy:                    sortBy,
+		PhotosPerReview:           photosPerReview,
+		DownloadPhotos:            downloadPhotos,
+		PhotoSize:                 photoSize,
+		Debug:                     debug,
 	}, nil
 }
+
+// parseIntList parses a |-separated list of integers
+func parseIntList(value string) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.Split(value, "|") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"testing"
 
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,10 +21,15 @@ func TestNewConfig(t *testing.T) {
 				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"en"},
-				FileType:    "csv",
-				ProxyHost:   "",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 		{
@@ -39,10 +45,15 @@ func TestNewConfig(t *testing.T) {
 				"LANGUAGES":    "en|fr|de",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"en", "fr", "de"},
-				FileType:    "csv",
-				ProxyHost:   "",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en", "fr", "de"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 		{
@@ -52,10 +63,15 @@ func TestNewConfig(t *testing.T) {
 				"LANGUAGES":    "fr",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"fr"},
-				FileType:    "csv",
-				ProxyHost:   "",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"fr"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 		{
@@ -65,10 +81,15 @@ func TestNewConfig(t *testing.T) {
 				"FILETYPE":     "json",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"en"},
-				FileType:    "json",
-				ProxyHost:   "",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "json",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 		{
@@ -78,10 +99,15 @@ func TestNewConfig(t *testing.T) {
 				"FILETYPE":     "csv",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"en"},
-				FileType:    "csv",
-				ProxyHost:   "",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 		{
@@ -91,10 +117,15 @@ func TestNewConfig(t *testing.T) {
 				"FILETYPE":     "JSON",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"en"},
-				FileType:    "json",
-				ProxyHost:   "",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "json",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 		{
@@ -106,6 +137,373 @@ func TestNewConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid file type",
 		},
+		{
+			name: "ndjson FILETYPE with NDJSON_METADATA",
+			envVars: map[string]string{
+				"LOCATION_URL":    "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"FILETYPE":        "NDJSON",
+				"NDJSON_METADATA": "true",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "ndjson",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				NDJSONMetadata:    true,
+			},
+		},
+		{
+			name: "parquet FILETYPE",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"FILETYPE":     "parquet",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "parquet",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "sqlite FILETYPE",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"FILETYPE":     "SQLite",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "sqlite",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "xlsx FILETYPE",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"FILETYPE":     "xlsx",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "xlsx",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "csv format settings",
+			envVars: map[string]string{
+				"LOCATION_URL":  "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CSV_COLUMNS":   "id|createdDate| userProfile.displayName |photoCount",
+				"CSV_DELIMITER": "tab",
+				"CSV_QUOTE":     "ALL",
+				"CSV_BOM":       "true",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				CSVColumns:        []string{"id", "createdDate", "userProfile.displayName", "photoCount"},
+				CSVDelimiter:      '\t',
+				CSVQuoteAll:       true,
+				CSVBOM:            true,
+			},
+		},
+		{
+			name: "unknown CSV_COLUMNS column returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CSV_COLUMNS":  "title|userProfile.shoeSize",
+			},
+			expectError: true,
+			errorMsg:    "invalid CSV_COLUMNS",
+		},
+		{
+			name: "multi-character CSV_DELIMITER returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":  "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CSV_DELIMITER": ";;",
+			},
+			expectError: true,
+			errorMsg:    "invalid CSV_DELIMITER",
+		},
+		{
+			name: "invalid CSV_QUOTE returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CSV_QUOTE":    "some",
+			},
+			expectError: true,
+			errorMsg:    "invalid CSV_QUOTE",
+		},
+		{
+			name: "PER_LANGUAGE scrapes each language separately",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"LANGUAGES":    "en|fr",
+				"PER_LANGUAGE": "true",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en", "fr"},
+				PerLanguage:       true,
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "invalid PER_LANGUAGE returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"PER_LANGUAGE": "yes please",
+			},
+			expectError: true,
+			errorMsg:    "invalid PER_LANGUAGE",
+		},
+		{
+			name: "MACHINE_TRANSLATION=false disables machine translation",
+			envVars: map[string]string{
+				"LOCATION_URL":        "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"MACHINE_TRANSLATION": "false",
+			},
+			expected: &Config{
+				LocationURL:               "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:                 []string{"en"},
+				FileType:                  "csv",
+				CheckpointFile:            "checkpoint.json",
+				RetryAttempts:             5,
+				Concurrency:               1,
+				RequestsPerSecond:         1,
+				BatchConcurrency:          1,
+				DisableMachineTranslation: true,
+			},
+		},
+		{
+			name: "invalid MACHINE_TRANSLATION returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":        "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"MACHINE_TRANSLATION": "sometimes",
+			},
+			expectError: true,
+			errorMsg:    "invalid MACHINE_TRANSLATION",
+		},
+		{
+			name: "ORIGINAL_TEXT=true fetches the original text",
+			envVars: map[string]string{
+				"LOCATION_URL":  "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"ORIGINAL_TEXT": "true",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				OriginalText:      true,
+			},
+		},
+		{
+			name: "invalid ORIGINAL_TEXT returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":  "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"ORIGINAL_TEXT": "maybe",
+			},
+			expectError: true,
+			errorMsg:    "invalid ORIGINAL_TEXT",
+		},
+		{
+			name: "RATINGS, TRIP_TYPES, MONTHS and SORT are parsed",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"RATINGS":      "1|2",
+				"TRIP_TYPES":   "business|Solo",
+				"MONTHS":       "12|1|2",
+				"SORT":         "helpful",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				Filters:           tripadvisor.ReviewFilters{Ratings: []int{1, 2}, TripTypes: []string{"BUSINESS", "SOLO"}, Months: []int{12, 1, 2}},
+				SortBy:            tripadvisor.SortByHelpful,
+			},
+		},
+		{
+			name: "invalid RATINGS returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"RATINGS":      "1|two",
+			},
+			expectError: true,
+			errorMsg:    "invalid RATINGS",
+		},
+		{
+			name: "out of range rating returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"RATINGS":      "0",
+			},
+			expectError: true,
+			errorMsg:    "invalid filters",
+		},
+		{
+			name: "unknown TRIP_TYPES returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"TRIP_TYPES":   "pets",
+			},
+			expectError: true,
+			errorMsg:    "invalid filters",
+		},
+		{
+			name: "invalid MONTHS returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"MONTHS":       "13",
+			},
+			expectError: true,
+			errorMsg:    "invalid filters",
+		},
+		{
+			name: "invalid SORT returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"SORT":         "oldest",
+			},
+			expectError: true,
+			errorMsg:    "invalid SORT",
+		},
+		{
+			name: "SORT other than recent with SINCE returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"SINCE":        "2025-01-01",
+				"SORT":         "helpful",
+			},
+			expectError: true,
+			errorMsg:    "SORT must be recent",
+		},
+		{
+			name: "PHOTOS_PER_REVIEW, DOWNLOAD_PHOTOS and PHOTO_SIZE are parsed",
+			envVars: map[string]string{
+				"LOCATION_URL":      "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"PHOTOS_PER_REVIEW": "20",
+				"DOWNLOAD_PHOTOS":   "true",
+				"PHOTO_SIZE":        "800x600",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				PhotosPerReview:   20,
+				DownloadPhotos:    true,
+				PhotoSize:         tripadvisor.PhotoSize{Width: 800, Height: 600},
+			},
+		},
+		{
+			name: "invalid PHOTOS_PER_REVIEW returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":      "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"PHOTOS_PER_REVIEW": "0",
+			},
+			expectError: true,
+			errorMsg:    "invalid PHOTOS_PER_REVIEW",
+		},
+		{
+			name: "invalid DOWNLOAD_PHOTOS returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":    "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"DOWNLOAD_PHOTOS": "all",
+			},
+			expectError: true,
+			errorMsg:    "invalid DOWNLOAD_PHOTOS",
+		},
+		{
+			name: "invalid PHOTO_SIZE returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":    "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"DOWNLOAD_PHOTOS": "true",
+				"PHOTO_SIZE":      "large",
+			},
+			expectError: true,
+			errorMsg:    "invalid PHOTO_SIZE",
+		},
+		{
+			name: "DEBUG logs the raw responses",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"DEBUG":        "true",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				Debug:             true,
+			},
+		},
+		{
+			name: "invalid DEBUG returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"DEBUG":        "verbose",
+			},
+			expectError: true,
+			errorMsg:    "invalid DEBUG",
+		},
+		{
+			name: "invalid NDJSON_METADATA returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":    "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"FILETYPE":        "ndjson",
+				"NDJSON_METADATA": "maybe",
+			},
+			expectError: true,
+			errorMsg:    "invalid NDJSON_METADATA",
+		},
 		{
 			name: "PROXY_HOST is passed through",
 			envVars: map[string]string{
@@ -113,12 +511,254 @@ func TestNewConfig(t *testing.T) {
 				"PROXY_HOST":   "http://proxy:8080",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
-				Languages:   []string{"en"},
-				FileType:    "csv",
-				ProxyHost:   "http://proxy:8080",
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "http://proxy:8080",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "CHECKPOINT_FILE is passed through",
+			envVars: map[string]string{
+				"LOCATION_URL":    "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CHECKPOINT_FILE": "/data/beau_rivage.checkpoint.json",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "/data/beau_rivage.checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "RETRY_ATTEMPTS is parsed",
+			envVars: map[string]string{
+				"LOCATION_URL":   "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"RETRY_ATTEMPTS": "10",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     10,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "invalid RETRY_ATTEMPTS returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":   "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"RETRY_ATTEMPTS": "0",
+			},
+			expectError: true,
+			errorMsg:    "invalid RETRY_ATTEMPTS",
+		},
+		{
+			name: "concurrency settings are parsed",
+			envVars: map[string]string{
+				"LOCATION_URL":        "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CONCURRENCY":         "4",
+				"REQUESTS_PER_SECOND": "2.5",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       4,
+				RequestsPerSecond: 2.5,
+				BatchConcurrency:  1,
 			},
 		},
+		{
+			name: "invalid CONCURRENCY returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"CONCURRENCY":  "many",
+			},
+			expectError: true,
+			errorMsg:    "invalid CONCURRENCY",
+		},
+		{
+			name: "invalid REQUESTS_PER_SECOND returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":        "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"REQUESTS_PER_SECOND": "-1",
+			},
+			expectError: true,
+			errorMsg:    "invalid REQUESTS_PER_SECOND",
+		},
+		{
+			name: "SINCE is passed through",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"SINCE":        "2025-01-31",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				Since:             "2025-01-31",
+			},
+		},
+		{
+			name: "SINCE_FILE is passed through",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"SINCE_FILE":   "previous/reviews.json",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				ProxyHost:         "",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				SinceFile:         "previous/reviews.json",
+			},
+		},
+		{
+			name: "invalid SINCE returns error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"SINCE":        "31/01/2025",
+			},
+			expectError: true,
+			errorMsg:    "invalid SINCE",
+		},
+		{
+			name: "SINCE and SINCE_FILE together return error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"SINCE":        "2025-01-31",
+				"SINCE_FILE":   "previous/reviews.json",
+			},
+			expectError: true,
+			errorMsg:    "mutually exclusive",
+		},
+		{
+			name: "URL_FILE enables batch mode",
+			envVars: map[string]string{
+				"URL_FILE":          "urls.txt",
+				"BATCH_CONCURRENCY": "3",
+			},
+			expected: &Config{
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				URLFile:           "urls.txt",
+				BatchConcurrency:  3,
+			},
+		},
+		{
+			name: "LOCATION_URL and URL_FILE together return error",
+			envVars: map[string]string{
+				"LOCATION_URL": "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"URL_FILE":     "urls.txt",
+			},
+			expectError: true,
+			errorMsg:    "mutually exclusive",
+		},
+		{
+			name: "SINCE_FILE in batch mode returns error",
+			envVars: map[string]string{
+				"URL_FILE":   "urls.txt",
+				"SINCE_FILE": "previous/reviews.json",
+			},
+			expectError: true,
+			errorMsg:    "SINCE_FILE is not supported in batch mode",
+		},
+		{
+			name: "invalid BATCH_CONCURRENCY returns error",
+			envVars: map[string]string{
+				"URL_FILE":          "urls.txt",
+				"BATCH_CONCURRENCY": "0",
+			},
+			expectError: true,
+			errorMsg:    "invalid BATCH_CONCURRENCY",
+		},
+		{
+			name: "REGIONAL_ENDPOINT is parsed",
+			envVars: map[string]string{
+				"LOCATION_URL":      "https://www.tripadvisor.fr/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"REGIONAL_ENDPOINT": "true",
+			},
+			expected: &Config{
+				LocationURL:       "https://www.tripadvisor.fr/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+				RegionalEndpoint:  true,
+			},
+		},
+		{
+			name: "invalid REGIONAL_ENDPOINT returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":      "https://www.tripadvisor.fr/Hotel_Review-g188107-d231860-Reviews-Test.html",
+				"REGIONAL_ENDPOINT": "sometimes",
+			},
+			expectError: true,
+			errorMsg:    "invalid REGIONAL_ENDPOINT",
+		},
+		{
+			name: "LOCATION_TYPE is parsed",
+			envVars: map[string]string{
+				"LOCATION_URL":  "d231860",
+				"LOCATION_TYPE": "hotel",
+			},
+			expected: &Config{
+				LocationURL:       "d231860",
+				LocationType:      "HOTEL",
+				Languages:         []string{"en"},
+				FileType:          "csv",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
+			},
+		},
+		{
+			name: "invalid LOCATION_TYPE returns error",
+			envVars: map[string]string{
+				"LOCATION_URL":  "d231860",
+				"LOCATION_TYPE": "CRUISE",
+			},
+			expectError: true,
+			errorMsg:    "invalid LOCATION_TYPE",
+		},
 		{
 			name: "all env vars set",
 			envVars: map[string]string{
@@ -128,10 +768,15 @@ func TestNewConfig(t *testing.T) {
 				"PROXY_HOST":   "socks5://proxy:1080",
 			},
 			expected: &Config{
-				LocationURL: "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
-				Languages:   []string{"en", "fr", "de", "es", "pt"},
-				FileType:    "json",
-				ProxyHost:   "socks5://proxy:1080",
+				LocationURL:       "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+				Languages:         []string{"en", "fr", "de", "es", "pt"},
+				FileType:          "json",
+				ProxyHost:         "socks5://proxy:1080",
+				CheckpointFile:    "checkpoint.json",
+				RetryAttempts:     5,
+				Concurrency:       1,
+				RequestsPerSecond: 1,
+				BatchConcurrency:  1,
 			},
 		},
 	}
@@ -140,7 +785,7 @@ func TestNewConfig(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// t.Setenv automatically restores the original value after the test
 			// and unsets vars that were not previously set
-			for _, key := range []string{"LOCATION_URL", "LANGUAGES", "FILETYPE", "PROXY_HOST"} {
+			for _, key := range []string{"LOCATION_URL", "LANGUAGES", "FILETYPE", "PROXY_HOST", "CHECKPOINT_FILE", "RETRY_ATTEMPTS", "CONCURRENCY", "REQUESTS_PER_SECOND", "SINCE", "SINCE_FILE", "URL_FILE", "BATCH_CONCURRENCY", "REGIONAL_ENDPOINT", "LOCATION_TYPE", "NDJSON_METADATA", "CSV_COLUMNS", "CSV_DELIMITER", "CSV_QUOTE", "CSV_BOM", "PER_LANGUAGE", "MACHINE_TRANSLATION", "ORIGINAL_TEXT", "RATINGS", "TRIP_TYPES", "MONTHS", "SORT", "PHOTOS_PER_REVIEW", "DOWNLOAD_PHOTOS", "PHOTO_SIZE", "DEBUG"} {
 				t.Setenv(key, "")
 			}
 			for key, value := range tt.envVars {
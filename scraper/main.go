@@ -1,46 +1,42 @@
 package main
 
 import (
-	"encoding/csv"
+	"context"
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/internal/batch"
 	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/internal/config"
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/exitcode"
 	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor"
 	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/utils"
 )
 
+// manifestFileName is the name of the summary written at the end of a batch run
+const manifestFileName = "manifest.json"
+
 func main() {
-	// Scraper variables
-	var allReviews []tripadvisor.Review
-	var michelinInfo *tripadvisor.MichelinInfo
+	// Cancel the in-flight requests on SIGINT or SIGTERM, e.g. when the container is stopped.
+	// A second signal kills the process right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
 	config, err := config.NewConfig()
 	if err != nil {
 		log.Fatalf("Error creating scrape config: %v", err)
 	}
 
-	// Get the query type from the URL
-	queryType := tripadvisor.GetURLType(config.LocationURL)
-	if queryType == "" {
-		log.Fatal("Invalid URL")
-	}
-	log.Printf("Location Type: %s", queryType)
-
-	// Parse the location ID and location name from the URL
-	locationID, geoID, locationName, err := tripadvisor.ParseURL(config.LocationURL, queryType)
-	if err != nil {
-		log.Fatalf("Error parsing URL: %v", err)
-	}
-	log.Printf("Location ID: %d", locationID)
-	log.Printf("Location Name: %s", locationName)
-
-	// Get the query ID for the given query type.
-	queryID := tripadvisor.GetQueryID(queryType)
+	// Retry the requests the configured number of times, with the default delays
+	retryPolicy := tripadvisor.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = config.RetryAttempts
 
 	// The default HTTP client
 	client := &http.Client{
@@ -64,92 +60,69 @@ func main() {
 		log.Printf("Proxy IP: %s", ip)
 	}
 
-	// Fetch the review count for the given location ID
-	reviewCount, err := tripadvisor.FetchReviewCount(client, locationID, geoID, queryType, config.Languages)
-	if err != nil {
-		log.Fatalf("Error fetching review count: %v", err)
+	// The HTTP client and the rate limiter are shared by every location of the run
+	s := &scraper{
+		client:      client,
+		config:      config,
+		limiter:     tripadvisor.NewRateLimiter(config.RequestsPerSecond, config.Concurrency),
+		retryPolicy: retryPolicy,
 	}
-	if reviewCount == 0 {
-		log.Fatalf("No reviews found for location ID %d", locationID)
-	}
-	log.Printf("Review count: %d", reviewCount)
 
-	// Create a file to save the reviews data
-	fileName := fmt.Sprintf("reviews.%s", config.FileType)
-	fileHandle, err := os.Create(fileName)
-	if err != nil {
-		log.Fatalf("Error creating file %s: %v", fileName, err)
+	if config.URLFile != "" {
+		runBatch(ctx, s)
+		return
 	}
-	defer fileHandle.Close()
-
-	// Calculate the number of iterations required to fetch all reviews
-	iterations := tripadvisor.CalculateIterations(uint32(reviewCount))
-	log.Printf("Total Iterations: %d", iterations)
-
-	// Create a slice to store the data to be written to the CSV file
-	dataToWrite := make([][]string, 0, reviewCount)
 
-	// Scrape the reviews
-	for i := range iterations {
+	if _, err := s.scrapeLocation(ctx, config.LocationURL, false, log.Default()); err != nil {
+		if ctx.Err() != nil {
+			log.Println(err)
+			os.Exit(exitcode.Interrupted)
+		}
+		log.Fatal(err)
+	}
 
-		// Introduce random delay to avoid getting blocked. The delay is between 1 and 5 seconds
-		delay := rand.Intn(5) + 1
-		log.Printf("Iteration: %d. Delaying for %d seconds", i, delay)
-		time.Sleep(time.Duration(delay) * time.Second)
+	log.Println("Scraping completed")
+}
 
-		// Calculate the offset for the current iteration
-		offset := tripadvisor.CalculateOffset(i)
+// runBatch scrapes every location listed in the URL file and writes the manifest of the run
+func runBatch(ctx context.Context, s *scraper) {
+	urls, err := batch.ReadURLs(s.config.URLFile)
+	if err != nil {
+		log.Fatalf("Error reading URL file: %v", err)
+	}
+	log.Printf("Scraping %d locations with %d in parallel", len(urls), s.config.BatchConcurrency)
 
-		// Make the request to the TripAdvisor GraphQL endpoint
-		resp, err := tripadvisor.MakeRequest(client, queryID, queryType, config.Languages, locationID, geoID, offset, 20)
-		if err != nil {
-			log.Fatalf("Error making request at iteration %d: %v", i, err)
+	manifest := batch.Run(urls, s.config.BatchConcurrency, func(url string) batch.Result {
+		// Skip the locations that were not started before the run was interrupted
+		if ctx.Err() != nil {
+			return batch.Result{Error: "interrupted before starting"}
 		}
 
-		// Extract reviews using the shared helper (handles both ReviewsProxy and Locations paths)
-		reviews := tripadvisor.ExtractReviews(resp)
+		logger := log.New(os.Stderr, fmt.Sprintf("[%s] ", url), log.LstdFlags)
 
-		// Extract Michelin info once from the first response that contains it
-		if michelinInfo == nil {
-			michelinInfo = tripadvisor.ExtractMichelinInfo(resp)
+		result, err := s.scrapeLocation(ctx, url, true, logger)
+		if err != nil {
+			logger.Printf("Error scraping location: %v", err)
+			result.Error = err.Error()
+			return result
 		}
 
-		// Append the reviews to the allReviews slice
-		allReviews = append(allReviews, reviews...)
+		result.Success = true
+		return result
+	})
 
-		if config.FileType == "csv" {
-			for _, r := range reviews {
-				dataToWrite = append(dataToWrite, tripadvisor.ReviewToCSVRow(r, locationName, michelinInfo))
-			}
-		}
+	if err := manifest.WriteFile(manifestFileName); err != nil {
+		log.Fatalf("Error writing manifest: %v", err)
 	}
+	log.Printf("Manifest written to %s", manifestFileName)
 
-	if config.FileType == "csv" {
-		writer := csv.NewWriter(fileHandle)
-
-		// Write CSV headers (includes Michelin columns when Michelin data is present)
-		if err := writer.Write(tripadvisor.CSVHeaders(michelinInfo != nil)); err != nil {
-			log.Fatalf("Error writing header to csv: %v", err)
-		}
-
-		// Write all review rows
-		if err := writer.WriteAll(dataToWrite); err != nil {
-			log.Fatalf("Error writing data to csv: %v", err)
-		}
+	if ctx.Err() != nil {
+		log.Printf("Scraping interrupted: %d succeeded, %d failed or interrupted", manifest.Succeeded, manifest.Failed)
+		os.Exit(exitcode.Interrupted)
 	}
 
-	// If the file type is JSON, write the complete scrape result (reviews + Michelin data)
-	if config.FileType == "json" {
-		tripadvisor.SortReviewsByDate(allReviews)
-		result := &tripadvisor.ScrapeResult{
-			Reviews:  allReviews,
-			Michelin: michelinInfo,
-		}
-		if err := tripadvisor.WriteScrapeResultToJSONFile(result, fileHandle); err != nil {
-			log.Fatalf("Error writing data to JSON file: %v", err)
-		}
+	log.Printf("Scraping completed: %d succeeded, %d failed", manifest.Succeeded, manifest.Failed)
+	if manifest.Failed > 0 {
+		os.Exit(1)
 	}
-
-	log.Printf("Data written to %s", fileName)
-	log.Println("Scraping completed")
 }
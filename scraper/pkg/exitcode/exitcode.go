@@ -0,0 +1,6 @@
+// Package exitcode holds the exit codes of the scraper that the programs running it, such as the container provisioner,
+// rely on. It has no dependency besides the standard library, so it can be imported without pulling in the scraper.
+package exitcode
+
+// Interrupted is the exit code of a scrape interrupted by SIGINT or SIGTERM after writing its partial output
+const Interrupted = 3
@@ -0,0 +1,55 @@
+package tripadvisor
+
+import "encoding/json"
+
+// Types of the flight of an airline review
+const (
+	FlightTypeInternational = "INTERNATIONAL"
+	FlightTypeDomestic      = "DOMESTIC"
+)
+
+// UnmarshalJSON decodes a response, along with the keywords returned next to the reviews of an airline
+func (r *Response) UnmarshalJSON(data []byte) error {
+	// The alias has no UnmarshalJSON method, which would otherwise be called recursively
+	type response Response
+	if err := json.Unmarshal(data, (*response)(r)); err != nil {
+		return err
+	}
+
+	r.Keywords = nil
+
+	// The keywords are decoded apart, which leaves the shape of Data as it is.
+	// Only the responses of airlines carry them, so the others are not decoded twice.
+	if len(r.Data.ReviewsProxy) == 0 {
+		return nil
+	}
+
+	var keywords struct {
+		Data struct {
+			ReviewsProxy []struct {
+				Keywords []ReviewKeyword `json:"keywords"`
+			} `json:"ReviewsProxy_getReviewListPageForLocation"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for _, proxy := range keywords.Data.ReviewsProxy {
+		r.Keywords = append(r.Keywords, proxy.Keywords...)
+	}
+	return nil
+}
+
+// ExtractKeywords extracts the review keywords from API responses.
+// Returns nil if no keywords are present, which is the case of every location type but airlines.
+func ExtractKeywords(responses *Responses) []ReviewKeyword {
+	if responses == nil {
+		return nil
+	}
+	for _, resp := range *responses {
+		if len(resp.Keywords) > 0 {
+			return resp.Keywords
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponsesUnmarshalAirlineReviews(t *testing.T) {
+	body := `[{"data":{"ReviewsProxy_getReviewListPageForLocation":[{"totalCount":2,` +
+		`"keywords":[{"keyword":"legroom","count":12},{"keyword":"crew","count":8}],` +
+		`"reviews":[{"id":1,"rating":4,"flight":{"origin":{"code":"GVA","name":"Geneva"},"destination":{"code":"IST"},"cabinClass":"ECONOMY","flightType":"INTERNATIONAL","aircraft":"Airbus A320"},` +
+		`"additionalRatings":[{"rating":2,"ratingLabel":"Legroom"},{"rating":4,"ratingLabel":"Seat comfort"},{"rating":3,"ratingLabel":"Check-in and boarding"}]},` +
+		`{"id":2,"rating":5}]}]}}]`
+
+	var responses Responses
+	assert.NoError(t, json.Unmarshal([]byte(body), &responses))
+
+	assert.Equal(t, []ReviewKeyword{{Keyword: "legroom", Count: 12}, {Keyword: "crew", Count: 8}}, ExtractKeywords(&responses))
+	assert.Equal(t, 2, ExtractTotalCount(&responses))
+
+	reviews := ExtractReviews(&responses)
+	assert.Len(t, reviews, 2)
+	assert.Equal(t, &FlightInfo{
+		Origin:      Airport{Code: "GVA", Name: "Geneva"},
+		Destination: Airport{Code: "IST"},
+		CabinClass:  "ECONOMY",
+		FlightType:  FlightTypeInternational,
+		Aircraft:    "Airbus A320",
+	}, reviews[0].Flight)
+	assert.Equal(t, map[string]int{SubRatingLegroom: 2, SubRatingSeatComfort: 4, SubRatingCheckInAndBoarding: 3}, reviews[0].SubRatings)
+	assert.Nil(t, reviews[1].Flight)
+}
+
+func TestExtractKeywords(t *testing.T) {
+	assert.Nil(t, ExtractKeywords(nil))
+	assert.Nil(t, ExtractKeywords(&Responses{}))
+
+	var responses Responses
+	assert.NoError(t, json.Unmarshal([]byte(`[{"data":{"locations":[{"locationId":1,"reviewListPage":{"totalCount":0,"reviews":[]}}]}}]`), &responses))
+	assert.Nil(t, ExtractKeywords(&responses))
+}
+
+func TestCSVReviewWriterAirline(t *testing.T) {
+	review := Review{
+		ID:         7,
+		Rating:     3,
+		Flight:     &FlightInfo{Origin: Airport{Code: "GVA"}, Destination: Airport{Code: "ZRH"}, CabinClass: "BUSINESS", FlightType: FlightTypeDomestic},
+		SubRatings: map[string]int{SubRatingLegroom: 5},
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewCSVReviewWriterWithFormat(&buf, CSVFormat{Columns: []string{
+		"rating", "flight.origin.code", "flight.destination.code", "flight.cabinClass", "flight.flightType", "flight.aircraft",
+		"subRatings." + SubRatingLegroom, "subRatings." + SubRatingSeatComfort,
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Swiss", LocationType: LocationTypeAirline}))
+	assert.NoError(t, writer.Write([]Review{review}))
+	assert.NoError(t, writer.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "Rating,Origin,Destination,Cabin Class,Flight Type,Aircraft,Legroom Rating,Seat Comfort Rating", lines[0])
+	assert.Equal(t, "3,GVA,ZRH,BUSINESS,DOMESTIC,,5,", lines[1])
+}
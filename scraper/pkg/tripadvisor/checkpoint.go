@@ -0,0 +1,273 @@
+package tripadvisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CheckpointSettings are the settings of a scrape that a checkpoint only holds for, as they change the reviews
+// that are fetched or how they are written
+type CheckpointSettings struct {
+	LocationURL string   `json:"locationUrl"`
+	Languages   []string `json:"languages"`
+	FileType    string   `json:"fileType"`
+
+	// PerLanguage is set when each language is scraped separately
+	PerLanguage bool `json:"perLanguage,omitempty"`
+
+	// DisableMachineTranslation is set when the reviews were fetched as written, without their machine translation
+	DisableMachineTranslation bool `json:"disableMachineTranslation,omitempty"`
+
+	// OriginalText is set when each page was also fetched without machine translation
+	OriginalText bool `json:"originalText,omitempty"`
+
+	// Filters and SortBy select and order the reviews, so the offsets of the checkpoint only hold for the same ones.
+	// SortBy is the order the reviews were requested in, which incremental mode forces to SortByDate whatever the configured one.
+	Filters ReviewFilters `json:"filters,omitzero"`
+	SortBy  string        `json:"sortBy,omitempty"`
+
+	// CSVFormat is the format of a CSV output, whose header a resumed scrape can not change
+	CSVFormat CSVFormat `json:"csvFormat,omitzero"`
+}
+
+// Checkpoint records the progress of a scrape so that an interrupted run can be resumed.
+// The reviews themselves live in the output file, of which the checkpoint records the size after the last completed page.
+// The lists that grow with every page, the review IDs, the response times and the keywords, are appended to a journal
+// next to the checkpoint instead of being rewritten by every Save. They must only ever be appended to.
+type Checkpoint struct {
+	CheckpointSettings
+
+	PagesCompleted      uint32        `json:"pagesCompleted"`
+	LastCompletedOffset uint32        `json:"lastCompletedOffset"`
+	ReviewsWritten      int           `json:"reviewsWritten"`
+	OutputSize          int64         `json:"outputSize"`
+	Michelin            *MichelinInfo `json:"michelin,omitempty"`
+	UpdatedAt           time.Time     `json:"updatedAt"`
+
+	// LanguageIndex is the index in Languages of the language scraped when PerLanguage is set. PagesCompleted and
+	// LastCompletedOffset then count the pages of that language, and ReviewIDs lists the reviews written so far
+	// so that a review returned for several languages is only written once.
+	LanguageIndex int   `json:"languageIndex,omitempty"`
+	ReviewIDs     []int `json:"-"`
+
+	// Responses are the management response figures of the reviews written so far
+	Responses ResponseStats `json:"responses,omitzero"`
+
+	// Keywords are the keywords of the reviews of an airline, returned with the first page
+	Keywords []ReviewKeyword `json:"-"`
+
+	// JournalSize is the size of the journal once the lists of the last Save were appended to it.
+	// Anything written after it belongs to a run that stopped before saving the checkpoint, and is dropped.
+	JournalSize int64 `json:"journalSize,omitempty"`
+
+	// The number of review IDs and response times already in the journal, and whether the keywords are
+	journaledReviewIDs     int
+	journaledResponseTimes int
+	journaledKeywords      bool
+}
+
+// checkpointJournalEntry is a line of the journal of a checkpoint, holding what was added to its lists since the previous Save
+type checkpointJournalEntry struct {
+	ReviewIDs     []int           `json:"reviewIds,omitempty"`
+	ResponseTimes []time.Duration `json:"responseTimes,omitempty"`
+	Keywords      []ReviewKeyword `json:"keywords,omitempty"`
+}
+
+// checkpointJournalPath returns the path of the journal of the checkpoint stored at the given path,
+// such as checkpoint-journal.ndjson for checkpoint.json
+func checkpointJournalPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "-journal.ndjson"
+}
+
+// NewCheckpoint returns an empty checkpoint for a scrape with the given settings
+func NewCheckpoint(settings CheckpointSettings) *Checkpoint {
+	return &Checkpoint{CheckpointSettings: settings}
+}
+
+// LoadCheckpoint reads the checkpoint stored at the given path.
+// It returns nil without an error if the file does not exist.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading checkpoint file: %w", err)
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("error unmarshalling checkpoint file: %w", err)
+	}
+
+	if err := checkpoint.loadJournal(checkpointJournalPath(path)); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+// loadJournal reads the lists of the checkpoint back from the journal at the given path, up to JournalSize
+func (c *Checkpoint) loadJournal(path string) error {
+	if c.JournalSize == 0 {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error reading checkpoint journal: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(file, c.JournalSize))
+	for {
+		var entry checkpointJournalEntry
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading checkpoint journal: %w", err)
+		}
+
+		c.ReviewIDs = append(c.ReviewIDs, entry.ReviewIDs...)
+		c.Responses.ResponseTimes = append(c.Responses.ResponseTimes, entry.ResponseTimes...)
+		if entry.Keywords != nil {
+			c.Keywords = entry.Keywords
+		}
+	}
+
+	c.journaledReviewIDs = len(c.ReviewIDs)
+	c.journaledResponseTimes = len(c.Responses.ResponseTimes)
+	c.journaledKeywords = c.Keywords != nil
+	return nil
+}
+
+// Matches reports whether the checkpoint was created for a scrape with the given settings, so that it can be resumed
+func (c *Checkpoint) Matches(settings CheckpointSettings) bool {
+	return c.LocationURL == settings.LocationURL && slices.Equal(c.Languages, settings.Languages) && c.FileType == settings.FileType &&
+		c.PerLanguage == settings.PerLanguage && c.DisableMachineTranslation == settings.DisableMachineTranslation &&
+		c.OriginalText == settings.OriginalText && c.Filters.Equal(settings.Filters) && c.SortBy == settings.SortBy &&
+		c.CSVFormat.Equal(settings.CSVFormat)
+}
+
+// Record adds a completed page fetched at the given offset to the checkpoint.
+// reviewCount is the number of reviews written for the page and outputSize the size of the output file once they were written.
+func (c *Checkpoint) Record(offset uint32, reviewCount int, outputSize int64, michelin *MichelinInfo) {
+	c.PagesCompleted++
+	c.LastCompletedOffset = offset
+	c.ReviewsWritten += reviewCount
+	c.OutputSize = outputSize
+	if c.Michelin == nil {
+		c.Michelin = michelin
+	}
+}
+
+// Started reports whether the scrape recorded by the checkpoint has completed any page, i.e. whether it is resumed
+func (c *Checkpoint) Started() bool {
+	return c.PagesCompleted > 0 || c.LanguageIndex > 0
+}
+
+// NextLanguage moves a per-language checkpoint on to the first page of the next language
+func (c *Checkpoint) NextLanguage() {
+	c.LanguageIndex++
+	c.PagesCompleted = 0
+	c.LastCompletedOffset = 0
+}
+
+// Save writes the checkpoint to the given path, after appending what was added to its lists to the journal.
+// The file is written to a temporary file first and then renamed, so a crash never leaves a truncated checkpoint behind.
+func (c *Checkpoint) Save(path string) error {
+	if err := c.appendJournal(checkpointJournalPath(path)); err != nil {
+		return err
+	}
+
+	c.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling checkpoint: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary checkpoint file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("error closing checkpoint file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("error replacing checkpoint file: %w", err)
+	}
+
+	return nil
+}
+
+// appendJournal appends what was added to the lists of the checkpoint since the previous Save to the journal at the given path
+func (c *Checkpoint) appendJournal(path string) error {
+	entry := checkpointJournalEntry{
+		ReviewIDs:     c.ReviewIDs[c.journaledReviewIDs:],
+		ResponseTimes: c.Responses.ResponseTimes[c.journaledResponseTimes:],
+	}
+	if !c.journaledKeywords {
+		entry.Keywords = c.Keywords
+	}
+	if len(entry.ReviewIDs) == 0 && len(entry.ResponseTimes) == 0 && len(entry.Keywords) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling checkpoint journal entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening checkpoint journal: %w", err)
+	}
+
+	// Drop the entries appended by a run that stopped before saving the checkpoint, if any
+	if err := file.Truncate(c.JournalSize); err != nil {
+		file.Close()
+		return fmt.Errorf("error truncating checkpoint journal: %w", err)
+	}
+	if _, err := file.WriteAt(data, c.JournalSize); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing checkpoint journal: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing checkpoint journal: %w", err)
+	}
+
+	c.JournalSize += int64(len(data))
+	c.journaledReviewIDs = len(c.ReviewIDs)
+	c.journaledResponseTimes = len(c.Responses.ResponseTimes)
+	c.journaledKeywords = len(c.Keywords) > 0
+	return nil
+}
+
+// RemoveCheckpoint deletes the checkpoint stored at the given path and its journal, if any
+func RemoveCheckpoint(path string) error {
+	for _, name := range []string{path, checkpointJournalPath(path)} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error removing checkpoint file: %w", err)
+		}
+	}
+	return nil
+}
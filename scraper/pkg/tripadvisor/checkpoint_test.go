@@ -0,0 +1,242 @@
+package tripadvisor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	checkpoint := NewCheckpoint(CheckpointSettings{
+		LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+		Languages:   []string{"en", "fr"},
+		FileType:    "csv",
+		CSVFormat:   CSVFormat{Columns: []string{"id", "title"}, Delimiter: ';', BOM: true},
+	})
+	checkpoint.Record(0, 20, 4096, nil)
+	checkpoint.Record(20, 3, 4700, &MichelinInfo{AwardHeader: "MICHELIN Guide"})
+
+	assert.NoError(t, checkpoint.Save(path))
+
+	loaded, err := LoadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), loaded.PagesCompleted)
+	assert.Equal(t, uint32(20), loaded.LastCompletedOffset)
+	assert.Equal(t, 23, loaded.ReviewsWritten)
+	assert.Equal(t, int64(4700), loaded.OutputSize)
+	assert.Equal(t, &MichelinInfo{AwardHeader: "MICHELIN Guide"}, loaded.Michelin)
+	assert.True(t, loaded.Matches(checkpoint.CheckpointSettings))
+
+	// No temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, RemoveCheckpoint(path))
+	loaded, err = LoadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     *string
+		expectNil   bool
+		expectError bool
+	}{
+		{
+			name:      "missing file returns nil",
+			content:   nil,
+			expectNil: true,
+		},
+		{
+			name:        "corrupted file returns error",
+			content:     new("{not json"),
+			expectError: true,
+		},
+		{
+			name:    "valid file is loaded",
+			content: new(`{"locationUrl":"https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa","pagesCompleted":3}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.json")
+			if tt.content != nil {
+				assert.NoError(t, os.WriteFile(path, []byte(*tt.content), 0o644))
+			}
+
+			checkpoint, err := LoadCheckpoint(path)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, checkpoint)
+				return
+			}
+			assert.NotNil(t, checkpoint)
+		})
+	}
+}
+
+func TestCheckpointMatches(t *testing.T) {
+	settings := CheckpointSettings{
+		LocationURL:  "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+		Languages:    []string{"en"},
+		FileType:     "csv",
+		OriginalText: true,
+		Filters:      ReviewFilters{Ratings: []int{5}},
+		SortBy:       SortByDate,
+		CSVFormat:    CSVFormat{Columns: []string{"id", "title"}},
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(settings *CheckpointSettings)
+		expected bool
+	}{
+		{
+			name:     "same settings",
+			modify:   func(settings *CheckpointSettings) {},
+			expected: true,
+		},
+		{
+			name: "different location",
+			modify: func(settings *CheckpointSettings) {
+				settings.LocationURL = "https://www.tripadvisor.com/Hotel_Review-g188107-d231861-Reviews-Other.html"
+			},
+		},
+		{
+			name:   "different languages",
+			modify: func(settings *CheckpointSettings) { settings.Languages = []string{"en", "fr"} },
+		},
+		{
+			name:   "different file type",
+			modify: func(settings *CheckpointSettings) { settings.FileType = "json" },
+		},
+		{
+			name:   "languages scraped separately",
+			modify: func(settings *CheckpointSettings) { settings.PerLanguage = true },
+		},
+		{
+			name:   "machine translation disabled",
+			modify: func(settings *CheckpointSettings) { settings.DisableMachineTranslation = true },
+		},
+		{
+			name:   "original text not fetched",
+			modify: func(settings *CheckpointSettings) { settings.OriginalText = false },
+		},
+		{
+			name:   "different filters",
+			modify: func(settings *CheckpointSettings) { settings.Filters = ReviewFilters{Ratings: []int{4, 5}} },
+		},
+		{
+			name:   "different sort order",
+			modify: func(settings *CheckpointSettings) { settings.SortBy = "" },
+		},
+		{
+			name:   "different csv format",
+			modify: func(settings *CheckpointSettings) { settings.CSVFormat.Delimiter = ';' },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkpoint := NewCheckpoint(settings)
+
+			other := settings
+			tt.modify(&other)
+			assert.Equal(t, tt.expected, checkpoint.Matches(other))
+		})
+	}
+}
+
+func TestCheckpointNextLanguage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	checkpoint := NewCheckpoint(CheckpointSettings{
+		LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html",
+		Languages:   []string{"en", "fr"},
+		FileType:    "csv",
+		PerLanguage: true,
+	})
+	assert.False(t, checkpoint.Started())
+
+	checkpoint.Record(0, 2, 512, nil)
+	checkpoint.ReviewIDs = append(checkpoint.ReviewIDs, 1, 2)
+	checkpoint.NextLanguage()
+
+	// A scrape between two languages has no completed page of the current language, but is still resumed
+	assert.Equal(t, uint32(0), checkpoint.PagesCompleted)
+	assert.True(t, checkpoint.Started())
+
+	assert.NoError(t, checkpoint.Save(path))
+	loaded, err := LoadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.True(t, loaded.PerLanguage)
+	assert.Equal(t, 1, loaded.LanguageIndex)
+	assert.Equal(t, []int{1, 2}, loaded.ReviewIDs)
+	assert.Equal(t, 2, loaded.ReviewsWritten)
+}
+
+func TestCheckpointJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	journalPath := filepath.Join(filepath.Dir(path), "checkpoint-journal.ndjson")
+
+	checkpoint := NewCheckpoint(CheckpointSettings{
+		LocationURL: "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+		Languages:   []string{"en", "fr"},
+		FileType:    "csv",
+		PerLanguage: true,
+	})
+	checkpoint.Keywords = []ReviewKeyword{{Keyword: "legroom", Count: 12}}
+	checkpoint.ReviewIDs = append(checkpoint.ReviewIDs, 1, 2)
+	checkpoint.Responses.ResponseTimes = append(checkpoint.Responses.ResponseTimes, 24*time.Hour)
+	checkpoint.Record(0, 2, 512, nil)
+	assert.NoError(t, checkpoint.Save(path))
+
+	checkpoint.ReviewIDs = append(checkpoint.ReviewIDs, 3)
+	checkpoint.Record(20, 1, 768, nil)
+	assert.NoError(t, checkpoint.Save(path))
+
+	// The lists are appended to the journal, one line per Save with what was added since the previous one
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "reviewIds")
+	journal, err := os.ReadFile(journalPath)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"reviewIds":[1,2],"responseTimes":[86400000000000],"keywords":[{"keyword":"legroom","count":12}]}`+"\n"+
+		`{"reviewIds":[3]}`+"\n", string(journal))
+
+	// A run that stops between appending to the journal and saving the checkpoint leaves an entry the checkpoint does not know of
+	file, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"reviewIds":[4]}` + "\n")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	loaded, err := LoadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, loaded.ReviewIDs)
+	assert.Equal(t, []time.Duration{24 * time.Hour}, loaded.Responses.ResponseTimes)
+	assert.Equal(t, []ReviewKeyword{{Keyword: "legroom", Count: 12}}, loaded.Keywords)
+
+	// The next Save drops that entry
+	loaded.ReviewIDs = append(loaded.ReviewIDs, 5)
+	assert.NoError(t, loaded.Save(path))
+	loaded, err = LoadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 5}, loaded.ReviewIDs)
+
+	assert.NoError(t, RemoveCheckpoint(path))
+	assert.NoFileExists(t, journalPath)
+}
@@ -0,0 +1,386 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/utils"
+)
+
+// Client sends requests to the TripAdvisor GraphQL endpoint.
+// It is safe for concurrent use and never exits the process: every failure is returned as an error.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	domain     string
+	headers    http.Header
+	logger     *log.Logger
+	limiter    *RateLimiter
+	retry      RetryPolicy
+	debug      bool
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the HTTP client used to send the requests, e.g. one going through a proxy
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBaseURL sets the URL the requests are sent to instead of the GraphQL endpoint of the domain
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithDomain sets the TripAdvisor domain, such as www.tripadvisor.fr, whose GraphQL endpoint and headers are used by default
+func WithDomain(domain string) ClientOption {
+	return func(c *Client) {
+		c.domain = domain
+	}
+}
+
+// WithHeader sets a header on every request, replacing the default value of the header if any
+func WithHeader(key string, value string) ClientOption {
+	return func(c *Client) {
+		c.headers.Set(key, value)
+	}
+}
+
+// WithLogger sets the logger retries are reported to. A nil logger silences them.
+func WithLogger(logger *log.Logger) ClientOption {
+	return func(c *Client) {
+		if logger == nil {
+			logger = log.New(io.Discard, "", 0)
+		}
+		c.logger = logger
+	}
+}
+
+// WithRateLimiter sets the limiter every request, including retries, waits for before being sent
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithDebug logs the raw body of every response to the logger of the client if debug is true
+func WithDebug(debug bool) ClientOption {
+	return func(c *Client) {
+		c.debug = debug
+	}
+}
+
+// NewClient returns a Client configured with the given options.
+// By default it uses http.DefaultClient, sends the requests to www.tripadvisor.com without rate limit,
+// retries them according to DefaultRetryPolicy and reports the retries to the standard logger.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		httpClient: http.DefaultClient,
+		domain:     DefaultDomain,
+		headers:    make(http.Header),
+		logger:     log.Default(),
+		retry:      DefaultRetryPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ReviewsOptions selects the reviews returned by Client.Reviews
+type ReviewsOptions struct {
+	// Languages are the languages of the reviews. Defaults to English.
+	Languages []string
+
+	// Offset is the number of reviews to skip
+	Offset uint32
+
+	// Limit is the maximum number of reviews to return. Defaults to ReviewLimit.
+	Limit uint32
+
+	// Request holds the options applied to the request
+	Request RequestOptions
+
+	// OriginalText also fetches each page without machine translation, doubling the number of requests,
+	// and sets the original title, text and language of the reviews. It has no effect when machine translation is disabled.
+	OriginalText bool
+}
+
+// Reviews fetches a page of reviews of the location
+func (c *Client) Reviews(ctx context.Context, loc *Location, opts ReviewsOptions) (*Responses, error) {
+	languages := opts.Languages
+	if len(languages) == 0 {
+		languages = []string{"en"}
+	}
+
+	limit := opts.Limit
+	if limit == 0 {
+		limit = ReviewLimit
+	}
+
+	query := ReviewsQuery{
+		Languages:  languages,
+		LocationID: loc.LocationID,
+		GeoID:      loc.GeoID,
+		Offset:     opts.Offset,
+		Limit:      limit,
+	}
+
+	responses, err := c.makeRequest(ctx, loc.Type, query, opts.Request)
+	if err != nil || !opts.OriginalText || opts.Request.DisableMachineTranslation {
+		return responses, err
+	}
+
+	originalOpts := opts.Request
+	originalOpts.DisableMachineTranslation = true
+	originals, err := c.makeRequest(ctx, loc.Type, query, originalOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching original reviews: %w", err)
+	}
+	MergeOriginalText(ExtractReviews(responses), ExtractReviews(originals))
+
+	return responses, nil
+}
+
+// ReviewsSeq returns an iterator over the reviews of the location, starting at the offset of opts.
+// Pages of opts.Limit reviews are fetched lazily as the iteration advances, so only one page is held in memory
+// and breaking out of the loop stops the requests. A failed request yields its error once and ends the iteration.
+func (c *Client) ReviewsSeq(ctx context.Context, loc *Location, opts ReviewsOptions) iter.Seq2[Review, error] {
+	return func(yield func(Review, error) bool) {
+		pageOpts := opts
+		if pageOpts.Limit == 0 {
+			pageOpts.Limit = ReviewLimit
+		}
+
+		for {
+			responses, err := c.Reviews(ctx, loc, pageOpts)
+			if err != nil {
+				yield(Review{}, fmt.Errorf("error fetching reviews at offset %d: %w", pageOpts.Offset, err))
+				return
+			}
+
+			reviews := ExtractReviews(responses)
+			for _, review := range reviews {
+				if !yield(review, nil) {
+					return
+				}
+			}
+
+			// A short page is the last one
+			pageOpts.Offset += pageOpts.Limit
+			if uint32(len(reviews)) < pageOpts.Limit {
+				return
+			}
+			if total := ExtractTotalCount(responses); total > 0 && pageOpts.Offset >= uint32(total) {
+				return
+			}
+		}
+	}
+}
+
+// ReviewCount fetches the number of reviews of the location in the given languages, English by default
+func (c *Client) ReviewCount(ctx context.Context, loc *Location, languages ...string) (int, error) {
+	count, err := c.CountReviews(ctx, loc, ReviewsOptions{Languages: languages})
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no reviews found for location ID %d", loc.LocationID)
+	}
+
+	return count, nil
+}
+
+// CountReviews fetches the number of reviews of the location selected by the languages and the request options of opts,
+// such as its filters. Unlike ReviewCount, a location without matching reviews is counted as 0 instead of failing.
+func (c *Client) CountReviews(ctx context.Context, loc *Location, opts ReviewsOptions) (int, error) {
+	opts.Offset = 0
+	opts.Limit = 1
+	opts.OriginalText = false
+	responses, err := c.Reviews(ctx, loc, opts)
+	if err != nil {
+		return 0, fmt.Errorf("error making request: %w", err)
+	}
+	return ExtractTotalCount(responses), nil
+}
+
+// makeRequest sends a request for a page of reviews of the given location type
+func (c *Client) makeRequest(ctx context.Context, locationType LocationType, query ReviewsQuery, opts RequestOptions) (*Responses, error) {
+
+	descriptor, err := LookupLocationType(locationType)
+	if err != nil {
+		return nil, err
+	}
+	query.QueryID = cmp.Or(query.QueryID, descriptor.QueryID)
+	query.PageName = cmp.Or(query.PageName, descriptor.PageName)
+	query.SortBy = opts.SortBy
+	query.DisableMachineTranslation = opts.DisableMachineTranslation
+	query.Filters = opts.Filters
+	query.PhotosPerReview = opts.PhotosPerReview
+
+	// Marshal the request body into JSON
+	jsonPayload, err := json.Marshal(descriptor.BuildRequest(query))
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling request body: %w", err)
+	}
+
+	domain := opts.Domain
+	if domain == "" {
+		domain = c.domain
+	}
+
+	responses := Responses{}
+	if err := c.post(ctx, domain, jsonPayload, &responses); err != nil {
+		return nil, err
+	}
+	return &responses, nil
+}
+
+// post sends the payload to the GraphQL endpoint of the given domain and unmarshals the response body into responses,
+// retrying the request according to the retry policy of the client
+func (c *Client) post(ctx context.Context, domain string, jsonPayload []byte, responses any) error {
+	return c.withRetries(ctx, func() error {
+		return c.send(ctx, domain, jsonPayload, responses)
+	})
+}
+
+// withRetries makes a request by calling attempt, waiting for the rate limiter before every attempt
+// and retrying the failed ones according to the retry policy of the client
+func (c *Client) withRetries(ctx context.Context, attempt func() error) error {
+	maxAttempts := max(c.retry.MaxAttempts, 1)
+
+	var lastErr error
+	for i := 1; i <= maxAttempts; i++ {
+
+		// Wait before retrying, honoring the Retry-After header sent by the server if any
+		if i > 1 {
+			var retryAfter time.Duration
+			var statusErr *StatusError
+			if errors.As(lastErr, &statusErr) {
+				retryAfter = statusErr.RetryAfter
+			}
+			delay := c.retry.Delay(i-1, retryAfter)
+			c.logger.Printf("Request failed (attempt %d/%d): %v. Retrying in %s", i-1, maxAttempts, lastErr, delay)
+			if err := Sleep(ctx, delay); err != nil {
+				return err
+			}
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		err := attempt()
+		if err == nil || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// userAgent is the browser the requests are sent as
+const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 11_0_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/87.0.4280.101 Safari/537.36"
+
+// send sends a single POST request with the given payload to the GraphQL endpoint of the given TripAdvisor domain
+// and unmarshals the response body into responses
+func (c *Client) send(ctx context.Context, domain string, jsonPayload []byte, responses any) error {
+	endpoint := c.baseURL
+	if endpoint == "" {
+		endpoint = GraphQLEndpoint(domain)
+	}
+
+	// Create a new request using http.NewRequest, setting the method to POST
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	requestedById, err := utils.GenerateRequestedByID()
+	if err != nil {
+		return fmt.Errorf("error generating X-Requested-By ID: %w", err)
+	}
+
+	// Set the necessary headers as per the original Axios request
+	req.Header.Set("Host", domain)
+	req.Header.Set("Origin", "https://"+domain)
+	req.Header.Set("Referer", "https://"+domain+"/Hotels")
+	req.Header.Set("Pragma", "no-cache")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Requested-By", requestedById)
+	req.Header.Set("Cookie", fmt.Sprintf("TAUnique=%s", requestedById))
+	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	req.Header.Set("Accepted-Encoding", "gzip, deflate, br")
+	req.Header.Set("Accept-Language", AcceptLanguage(domain))
+
+	// Apply the headers set on the client
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	// Send the request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check the response status code and classify the failure
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(resp)
+	}
+
+	// Read the response body
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if c.debug {
+		c.logger.Printf("Raw response:\n%s", responseBody)
+	}
+
+	// A body that does not match the expected structure means TripAdvisor changed its API
+	if err := json.Unmarshal(responseBody, responses); err != nil {
+		return fmt.Errorf("%w: %w", ErrSchemaChanged, err)
+	}
+
+	return nil
+}
+
+// Sleep waits for the given duration or until the context is done, in which case it returns the error of the context
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,344 @@
+package tripadvisor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientReviews(t *testing.T) {
+	var received struct {
+		header    http.Header
+		variables struct {
+			LocationID uint32 `json:"locationId"`
+			Offset     uint32 `json:"offset"`
+			Limit      uint32 `json:"limit"`
+			Language   string `json:"language"`
+			SortBy     string `json:"sortBy"`
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.header = r.Header
+		var batch []struct {
+			Variables json.RawMessage `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		assert.NoError(t, json.Unmarshal(batch[0].Variables, &received.variables))
+		fmt.Fprint(w, `[{"data":{"locations":[{"locationId":231860,"reviewListPage":{"totalCount":2,"reviews":[{"id":1},{"id":2}]}}]}}]`)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHeader("User-Agent", "review-bot/1.0"),
+		WithLogger(nil),
+	)
+	location := &Location{Type: "HOTEL", GeoID: 188107, LocationID: 231860}
+
+	responses, err := client.Reviews(context.Background(), location, ReviewsOptions{
+		Languages: []string{"fr"},
+		Offset:    40,
+		Request:   RequestOptions{SortBy: SortByDate},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Review{{ID: 1}, {ID: 2}}, ExtractReviews(responses))
+	assert.Equal(t, uint32(231860), received.variables.LocationID)
+	assert.Equal(t, uint32(40), received.variables.Offset)
+	assert.Equal(t, ReviewLimit, received.variables.Limit)
+	assert.Equal(t, "fr", received.variables.Language)
+	assert.Equal(t, SortByDate, received.variables.SortBy)
+	assert.Equal(t, "review-bot/1.0", received.header.Get("User-Agent"), "client headers replace the defaults")
+	assert.Equal(t, "https://www.tripadvisor.com", received.header.Get("Origin"))
+}
+
+func TestClientReviewCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		expected    int
+		expectError bool
+	}{
+		{
+			name:     "location with reviews",
+			body:     `[{"data":{"locations":[{"reviewListPage":{"totalCount":1234}}]}}]`,
+			expected: 1234,
+		},
+		{
+			name:        "location without reviews",
+			body:        `[{"data":{"locations":[{"reviewListPage":{"totalCount":0}}]}}]`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			client := NewClient(WithHTTPClient(newTestClient(t, server)))
+			count, err := client.ReviewCount(context.Background(), &Location{Type: "HOTEL", LocationID: 1}, "en")
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
+
+func TestClientStopsRetryingWhenContextIsDone(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithLogger(nil),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Reviews(ctx, &Location{Type: "AIRLINE", LocationID: 1}, ReviewsOptions{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClientWaitsForRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRateLimiter(NewRateLimiter(20, 1)))
+	location := &Location{Type: "HOTEL", LocationID: 1}
+
+	start := time.Now()
+	for range 3 {
+		_, err := client.Reviews(context.Background(), location, ReviewsOptions{})
+		assert.NoError(t, err)
+	}
+
+	// The first request uses the burst, the next two wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestClientFetchPages(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := newPagedServer(t, -1, &inFlight, &maxInFlight)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	location := &Location{Type: "HOTEL", GeoID: 1, LocationID: 1}
+
+	var offsets []int
+	err := client.FetchPages(context.Background(), location, 0, 5, 2, ReviewsOptions{}, func(page Page) error {
+		offsets = append(offsets, ExtractReviews(page.Responses)[0].ID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 20, 40, 60, 80}, offsets)
+}
+
+func TestClientFetchPagesCanceled(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := newPagedServer(t, -1, &inFlight, &maxInFlight)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	location := &Location{Type: "HOTEL", GeoID: 1, LocationID: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled int
+	err := client.FetchPages(ctx, location, 0, 50, 2, ReviewsOptions{}, func(page Page) error {
+		handled++
+		if handled == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, handled, 50)
+}
+
+// graphQLRequest is a request of a batch received by the server of newGraphQLServer
+type graphQLRequest struct {
+	Variables struct {
+		Variables
+		RoutesRequest []RouteRequest `json:"routesRequest"`
+	} `json:"variables"`
+	Extensions Extensions `json:"extensions"`
+}
+
+// reviewListPage is the page of reviews the server of newGraphQLServer answers a batch with.
+// A non-zero Status answers with that status code instead.
+type reviewListPage struct {
+	TotalCount int
+	Reviews    []Review
+	Status     int
+}
+
+// newGraphQLServer returns a fake GraphQL endpoint decoding every batch of requests it receives
+// and answering it with the page of location reviews returned by answer
+func newGraphQLServer(t *testing.T, answer func(batch []graphQLRequest) reviewListPage) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []graphQLRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+
+		page := answer(batch)
+		if page.Status != 0 {
+			w.WriteHeader(page.Status)
+			return
+		}
+
+		reviews := page.Reviews
+		if reviews == nil {
+			reviews = []Review{}
+		}
+		body, err := json.Marshal(reviews)
+		assert.NoError(t, err)
+		fmt.Fprintf(w, `[{"data":{"locations":[{"reviewListPage":{"totalCount":%d,"reviews":%s}}]}}]`, page.TotalCount, body)
+	}))
+}
+
+// newReviewListServer returns a test server paginating over totalCount reviews whose IDs are their positions.
+// The request for the page at failOffset fails with a 403.
+func newReviewListServer(t *testing.T, totalCount int, failOffset int, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return newGraphQLServer(t, func(batch []graphQLRequest) reviewListPage {
+		requests.Add(1)
+		offset, limit := int(batch[0].Variables.Offset), int(batch[0].Variables.Limit)
+		if offset == failOffset {
+			return reviewListPage{Status: http.StatusForbidden}
+		}
+
+		var reviews []Review
+		for id := offset; id < min(offset+limit, totalCount); id++ {
+			reviews = append(reviews, Review{ID: id})
+		}
+		return reviewListPage{TotalCount: totalCount, Reviews: reviews}
+	})
+}
+
+func TestClientReviewsSeq(t *testing.T) {
+	tests := []struct {
+		name             string
+		totalCount       int
+		failOffset       int
+		opts             ReviewsOptions
+		stopAfter        int
+		expectedIDs      []int
+		expectedRequests int32
+		expectError      bool
+	}{
+		{
+			name:             "all reviews",
+			totalCount:       45,
+			failOffset:       -1,
+			expectedIDs:      sequence(0, 45),
+			expectedRequests: 3,
+		},
+		{
+			name:             "last page is full",
+			totalCount:       40,
+			failOffset:       -1,
+			expectedIDs:      sequence(0, 40),
+			expectedRequests: 2,
+		},
+		{
+			name:             "starting at an offset with a smaller page size",
+			totalCount:       45,
+			failOffset:       -1,
+			opts:             ReviewsOptions{Offset: 30, Limit: 10},
+			expectedIDs:      sequence(30, 45),
+			expectedRequests: 2,
+		},
+		{
+			name:             "breaking early stops fetching",
+			totalCount:       45,
+			failOffset:       -1,
+			stopAfter:        5,
+			expectedIDs:      sequence(0, 5),
+			expectedRequests: 1,
+		},
+		{
+			name:             "failed page ends the iteration with its error",
+			totalCount:       45,
+			failOffset:       20,
+			expectedIDs:      sequence(0, 20),
+			expectedRequests: 2,
+			expectError:      true,
+		},
+		{
+			name:             "location without reviews",
+			totalCount:       0,
+			failOffset:       -1,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := newReviewListServer(t, tt.totalCount, tt.failOffset, &requests)
+			defer server.Close()
+
+			client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+			location := &Location{Type: "HOTEL", GeoID: 1, LocationID: 1}
+
+			var ids []int
+			var errs []error
+			for review, err := range client.ReviewsSeq(context.Background(), location, tt.opts) {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				ids = append(ids, review.ID)
+				if len(ids) == tt.stopAfter {
+					break
+				}
+			}
+
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedRequests, requests.Load())
+			if tt.expectError {
+				assert.Len(t, errs, 1)
+				assert.ErrorIs(t, errs[0], ErrBlocked)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+// sequence returns the integers from start up to (but excluding) end
+func sequence(start int, end int) []int {
+	var ids []int
+	for id := start; id < end; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,169 @@
+package tripadvisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrStopFetching can be returned by the page handler of FetchPagesConcurrently to stop fetching pages without failing
+var ErrStopFetching = errors.New("stop fetching")
+
+// Page is a single page of reviews fetched from TripAdvisor
+type Page struct {
+	Iteration uint32
+	Offset    uint32
+	Responses *Responses
+}
+
+// ConcurrencyOptions configures FetchPagesConcurrently
+type ConcurrencyOptions struct {
+	// Workers is the maximum number of requests in flight at the same time
+	Workers int
+
+	// Limiter is shared by all workers to cap the overall request rate. It may be nil.
+	Limiter *RateLimiter
+
+	// Request holds the options applied to every request
+	Request RequestOptions
+}
+
+// pageResult is what a worker reports back after fetching a page
+type pageResult struct {
+	page Page
+	err  error
+}
+
+// FetchPagesConcurrently fetches the pages from firstIteration up to (but excluding) iterations with a pool of workers.
+// Pages are handed to handle one at a time and in offset order, regardless of the order in which they arrive.
+// The first request error, or the first error returned by handle, stops the pool. Every page before the failed one
+// has been handled by then, so callers can checkpoint their progress from handle. If handle returns ErrStopFetching,
+// the pool stops and FetchPagesConcurrently returns nil.
+func FetchPagesConcurrently(client *http.Client, queryID string, queryType string, languages []string, locationID uint32, geoID uint32, firstIteration uint32, iterations uint32, opts ConcurrencyOptions, handle func(Page) error) error {
+	c := NewClient(WithHTTPClient(client), WithRateLimiter(opts.Limiter))
+	fetch := func(ctx context.Context, offset uint32) (*Responses, error) {
+		query := ReviewsQuery{
+			QueryID:    queryID,
+			Languages:  languages,
+			LocationID: locationID,
+			GeoID:      geoID,
+			Offset:     offset,
+			Limit:      ReviewLimit,
+		}
+		return c.makeRequest(ctx, LocationType(queryType), query, opts.Request)
+	}
+	return fetchPages(context.Background(), firstIteration, iterations, opts.Workers, fetch, handle)
+}
+
+// FetchPages is like FetchPagesConcurrently for the given location. The offset and limit of opts are ignored.
+// Requests wait for the rate limiter of the client, and the pool stops when the context is done.
+func (c *Client) FetchPages(ctx context.Context, loc *Location, firstIteration uint32, iterations uint32, workers int, opts ReviewsOptions, handle func(Page) error) error {
+	fetch := func(ctx context.Context, offset uint32) (*Responses, error) {
+		pageOpts := opts
+		pageOpts.Offset = offset
+		pageOpts.Limit = ReviewLimit
+		return c.Reviews(ctx, loc, pageOpts)
+	}
+	return fetchPages(ctx, firstIteration, iterations, workers, fetch, handle)
+}
+
+// fetchPages runs the worker pool of FetchPagesConcurrently, fetching each page with fetch
+func fetchPages(ctx context.Context, firstIteration uint32, iterations uint32, workers int, fetch func(ctx context.Context, offset uint32) (*Responses, error), handle func(Page) error) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers = max(workers, 1)
+	jobs := make(chan uint32)
+	results := make(chan pageResult)
+
+	// An iteration is only dispatched once a slot is free, and a slot is freed when a page is handled.
+	// At most workers pages are then fetched ahead of the next page to handle, which bounds the pages buffered below.
+	slots := make(chan struct{}, workers)
+
+	// Start the workers
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for iteration := range jobs {
+				offset := CalculateOffset(iteration)
+				responses, err := fetch(ctx, offset)
+
+				select {
+				case results <- pageResult{page: Page{Iteration: iteration, Offset: offset, Responses: responses}, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}
+
+	// Dispatch the iterations until all of them are dispatched or the pool is stopped
+	go func() {
+		defer close(jobs)
+		for iteration := firstIteration; iteration < iterations; iteration++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- iteration:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Reassemble the pages in offset order, buffering the ones that arrive early
+	pending := make(map[uint32]pageResult)
+	next := firstIteration
+	var firstErr error
+
+	for result := range results {
+		// Keep draining the results so that the workers can exit
+		if firstErr != nil {
+			continue
+		}
+
+		pending[result.page.Iteration] = result
+
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+
+			if result.err != nil {
+				firstErr = fmt.Errorf("error making request at iteration %d: %w", next, result.err)
+			} else if err := handle(result.page); err != nil {
+				firstErr = err
+			}
+
+			if firstErr != nil {
+				cancel()
+				break
+			}
+			next++
+			<-slots
+		}
+	}
+
+	if errors.Is(firstErr, ErrStopFetching) {
+		return nil
+	}
+
+	// The context was done before every page was handled
+	if firstErr == nil && next < iterations {
+		return ctx.Err()
+	}
+	return firstErr
+}
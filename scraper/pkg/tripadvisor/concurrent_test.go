@@ -0,0 +1,123 @@
+package tripadvisor
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newPagedServer returns a test server answering each request with a single review whose ID is the requested offset.
+// Responses are delayed randomly so that they complete out of order.
+func newPagedServer(t *testing.T, failOffset int, inFlight *atomic.Int32, maxInFlight *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return newGraphQLServer(t, func(batch []graphQLRequest) reviewListPage {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if current <= peak || maxInFlight.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+
+		offset := int(batch[0].Variables.Offset)
+
+		time.Sleep(time.Duration(rand.Intn(10)) * time.Millisecond)
+
+		if offset == failOffset {
+			return reviewListPage{Status: http.StatusForbidden}
+		}
+		return reviewListPage{Reviews: []Review{{ID: offset}}}
+	})
+}
+
+func TestFetchPagesConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := newPagedServer(t, -1, &inFlight, &maxInFlight)
+	defer server.Close()
+
+	var offsets []int
+	opts := ConcurrencyOptions{Workers: 3, Limiter: NewRateLimiter(1000, 3)}
+	err := FetchPagesConcurrently(newTestClient(t, server), HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 2, 12, opts, func(page Page) error {
+		reviews := ExtractReviews(page.Responses)
+		assert.Len(t, reviews, 1)
+		assert.Equal(t, int(page.Offset), reviews[0].ID)
+		offsets = append(offsets, reviews[0].ID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{40, 60, 80, 100, 120, 140, 160, 180, 200, 220}, offsets)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+}
+
+func TestFetchPagesBoundsPagesAhead(t *testing.T) {
+	const workers = 3
+
+	// The first page is slow, so the later pages would all be fetched and buffered if nothing held them back
+	var fetched, handled atomic.Int32
+	fetch := func(ctx context.Context, offset uint32) (*Responses, error) {
+		fetched.Add(1)
+		if offset == 0 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return &Responses{}, nil
+	}
+
+	var maxAhead int32
+	err := fetchPages(context.Background(), 0, 30, workers, fetch, func(page Page) error {
+		maxAhead = max(maxAhead, fetched.Load()-handled.Load())
+		handled.Add(1)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(30), handled.Load())
+	assert.LessOrEqual(t, maxAhead, int32(workers))
+}
+
+func TestFetchPagesConcurrentlyStopsOnRequestError(t *testing.T) {
+	useRetryPolicy(t, RetryPolicy{MaxAttempts: 1})
+
+	var inFlight, maxInFlight atomic.Int32
+	server := newPagedServer(t, 100, &inFlight, &maxInFlight)
+	defer server.Close()
+
+	var offsets []uint32
+	opts := ConcurrencyOptions{Workers: 4}
+	err := FetchPagesConcurrently(newTestClient(t, server), HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 0, 50, opts, func(page Page) error {
+		offsets = append(offsets, page.Offset)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.Contains(t, err.Error(), "iteration 5")
+	assert.Equal(t, []uint32{0, 20, 40, 60, 80}, offsets, "every page before the failed one is handled")
+}
+
+func TestFetchPagesConcurrentlyStopsOnHandlerError(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := newPagedServer(t, -1, &inFlight, &maxInFlight)
+	defer server.Close()
+
+	errDiskFull := errors.New("disk full")
+	handled := 0
+	opts := ConcurrencyOptions{Workers: 2}
+	err := FetchPagesConcurrently(newTestClient(t, server), HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 0, 50, opts, func(page Page) error {
+		handled++
+		if page.Iteration == 2 {
+			return errDiskFull
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, errDiskFull)
+	assert.Equal(t, 3, handled)
+}
@@ -0,0 +1,253 @@
+package tripadvisor
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownCSVColumn is returned for a CSV column path that matches neither a Review field nor a computed column
+var ErrUnknownCSVColumn = errors.New("unknown csv column")
+
+// DefaultCSVColumns are the columns of a CSV output when none are configured
+var DefaultCSVColumns = []string{"locationName", "title", "text", "rating", "year", "month", "day", "tripInfo.tripType", "tripInfo.stayDate"}
+
+// MichelinCSVColumns are appended to DefaultCSVColumns when Michelin data is present
+var MichelinCSVColumns = []string{"michelin.awardName", "michelin.yearOfAward"}
+
+// CSVFormat configures the columns and the dialect of a CSV output
+type CSVFormat struct {
+	// Columns are the paths of the columns, either the JSON field path of a Review field such as userProfile.displayName
+	// or a computed column (locationName, year, month, day, photoCount, photoUrls, mgmtResponse.responder,
+	// michelin.awardName, michelin.yearOfAward, or subRatings. followed by an aspect such as subRatings.cleanliness).
+	// Defaults to DefaultCSVColumns, followed by MichelinCSVColumns when Michelin data is present.
+	Columns []string `json:"columns,omitempty"`
+
+	// Delimiter separates the fields. Defaults to a comma.
+	Delimiter rune `json:"delimiter,omitempty"`
+
+	// QuoteAll quotes every field instead of only the ones containing a delimiter, a quote or a line break
+	QuoteAll bool `json:"quoteAll,omitempty"`
+
+	// BOM starts the output with a UTF-8 byte order mark, so that Excel detects the encoding
+	BOM bool `json:"bom,omitempty"`
+}
+
+// Equal reports whether both formats write the same columns in the same dialect
+func (f CSVFormat) Equal(other CSVFormat) bool {
+	return slices.Equal(f.Columns, other.Columns) && cmp.Or(f.Delimiter, ',') == cmp.Or(other.Delimiter, ',') &&
+		f.QuoteAll == other.QuoteAll && f.BOM == other.BOM
+}
+
+// csvColumn is a compiled CSV column
+type csvColumn struct {
+	header string
+	value  func(r Review, locationName string, michelin *MichelinInfo) string
+}
+
+// csvColumnHeaders are the headers of the well-known columns. Other columns are headed by their path.
+var csvColumnHeaders = map[string]string{
+	"locationName":                     "Location Name",
+	"title":                            "Title",
+	"text":                             "Text",
+	"rating":                           "Rating",
+	"year":                             "Year",
+	"month":                            "Month",
+	"day":                              "Day",
+	"tripInfo.tripType":                "Trip Type",
+	"tripInfo.stayDate":                "Stay Date",
+	"michelin.awardName":               "Michelin Award",
+	"michelin.yearOfAward":             "Michelin Year",
+	"id":                               "Review ID",
+	"createdDate":                      "Created Date",
+	"publishedDate":                    "Published Date",
+	"language":                         "Language",
+	"helpfulVotes":                     "Helpful Votes",
+	"labels":                           "Labels",
+	"photoCount":                       "Photo Count",
+	"photoUrls":                        "Photo URLs",
+	"locationId":                       "Location ID",
+	"userProfile.username":             "Username",
+	"userProfile.displayName":          "User",
+	"userProfile.isVerified":           "User Verified",
+	"originalTitle":                    "Original Title",
+	"originalText":                     "Original Text",
+	"originalLanguage":                 "Original Language",
+	"translationType":                  "Translation Type",
+	"mgmtResponse.text":                "Response Text",
+	"mgmtResponse.publishedDate":       "Response Date",
+	"mgmtResponse.language":            "Response Language",
+	"mgmtResponse.responder":           "Responder",
+	"mgmtResponse.connectionToSubject": "Responder Title",
+	"flight.origin.code":               "Origin",
+	"flight.destination.code":          "Destination",
+	"flight.cabinClass":                "Cabin Class",
+	"flight.flightType":                "Flight Type",
+	"flight.aircraft":                  "Aircraft",
+}
+
+// computedCSVColumns are the columns that are not a Review field
+var computedCSVColumns = map[string]func(r Review, locationName string, michelin *MichelinInfo) string{
+	"locationName": func(_ Review, locationName string, _ *MichelinInfo) string { return locationName },
+	"year":         func(r Review, _ string, _ *MichelinInfo) string { return substring(r.CreatedDate, 0, 4) },
+	"month":        func(r Review, _ string, _ *MichelinInfo) string { return substring(r.CreatedDate, 5, 7) },
+	"day":          func(r Review, _ string, _ *MichelinInfo) string { return substring(r.CreatedDate, 8, 10) },
+	"photoCount":   func(r Review, _ string, _ *MichelinInfo) string { return strconv.Itoa(len(r.PhotoIds)) },
+	"photoUrls":    func(r Review, _ string, _ *MichelinInfo) string { return strings.Join(r.PhotoURLs(), "; ") },
+	"mgmtResponse.responder": func(r Review, _ string, _ *MichelinInfo) string {
+		if r.MgmtResponse == nil {
+			return ""
+		}
+		return r.MgmtResponse.Responder()
+	},
+	"michelin.awardName": func(_ Review, _ string, michelin *MichelinInfo) string {
+		return joinMichelinAwards(michelin, func(a MichelinAward) string { return a.AwardName })
+	},
+	"michelin.yearOfAward": func(_ Review, _ string, michelin *MichelinInfo) string {
+		return joinMichelinAwards(michelin, func(a MichelinAward) string { return a.YearOfAward })
+	},
+}
+
+// ValidateCSVColumns returns an error wrapping ErrUnknownCSVColumn if a column path can not be resolved
+func ValidateCSVColumns(paths []string) error {
+	_, err := compileCSVColumns(paths)
+	return err
+}
+
+// compileCSVColumns resolves the column paths
+func compileCSVColumns(paths []string) ([]csvColumn, error) {
+	columns := make([]csvColumn, 0, len(paths))
+	for _, path := range paths {
+		header, ok := csvColumnHeaders[path]
+		if !ok {
+			header = path
+		}
+
+		if value, ok := computedCSVColumns[path]; ok {
+			columns = append(columns, csvColumn{header: header, value: value})
+			continue
+		}
+
+		// The sub-ratings are a map, so any aspect can be selected
+		if aspect, ok := strings.CutPrefix(path, "subRatings."); ok && aspect != "" {
+			columns = append(columns, csvColumn{header: subRatingHeader(aspect), value: subRatingCSVValue(aspect)})
+			continue
+		}
+
+		index, err := reviewFieldIndex(path)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, csvColumn{
+			header: header,
+			value: func(r Review, _ string, _ *MichelinInfo) string {
+				// A field below a nil pointer, such as the text of a missing management response, is empty
+				value, err := reflect.ValueOf(r).FieldByIndexErr(index)
+				if err != nil {
+					return ""
+				}
+				return formatCSVValue(value)
+			},
+		})
+	}
+	return columns, nil
+}
+
+// compileDefaultCSVColumns compiles the paths of default columns
+func compileDefaultCSVColumns(paths []string) []csvColumn {
+	// The default paths always resolve
+	columns, _ := compileCSVColumns(paths)
+	return columns
+}
+
+// reviewFieldIndex returns the index of the Review field at the given path of JSON field names
+func reviewFieldIndex(path string) ([]int, error) {
+	fieldType := reflect.TypeFor[Review]()
+	var index []int
+	for _, name := range strings.Split(path, ".") {
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCSVColumn, path)
+		}
+
+		field, ok := jsonField(fieldType, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCSVColumn, path)
+		}
+		index = append(index, field.Index...)
+		fieldType = field.Type
+	}
+	return index, nil
+}
+
+// jsonField returns the field of the struct type whose JSON name is name
+func jsonField(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// formatCSVValue formats a Review field as a CSV field. Lists are joined with "; " and objects are written as JSON.
+func formatCSVValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return formatCSVValue(v.Elem())
+	case reflect.Slice:
+		values := make([]string, 0, v.Len())
+		for i := range v.Len() {
+			values = append(values, formatCSVValue(v.Index(i)))
+		}
+		return strings.Join(values, "; ")
+	default:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+// joinMichelinAwards joins a field of every Michelin award with "; "
+func joinMichelinAwards(michelin *MichelinInfo, field func(MichelinAward) string) string {
+	if michelin == nil {
+		return ""
+	}
+	values := make([]string, 0, len(michelin.Awards))
+	for _, a := range michelin.Awards {
+		values = append(values, field(a))
+	}
+	return strings.Join(values, "; ")
+}
+
+// substring returns value[start:end], or an empty string if value is too short
+func substring(value string, start, end int) string {
+	if len(value) < end {
+		return ""
+	}
+	return value[start:end]
+}
@@ -0,0 +1,161 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCSVColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		columns     []string
+		expectError bool
+	}{
+		{
+			name:    "default columns",
+			columns: DefaultCSVColumns,
+		},
+		{
+			name:    "nested and computed columns",
+			columns: []string{"id", "userProfile.displayName", "userProfile.hometown.fallbackString", "location.placeType", "photoCount", "michelin.awardName"},
+		},
+		{
+			name:    "sub-ratings",
+			columns: []string{"id", "subRatings.cleanliness", "subRatings.legroom"},
+		},
+		{
+			name:        "unknown field",
+			columns:     []string{"title", "sentiment"},
+			expectError: true,
+		},
+		{
+			name:        "path below a scalar field",
+			columns:     []string{"title.length"},
+			expectError: true,
+		},
+		{
+			name:        "Go field name instead of the JSON name",
+			columns:     []string{"UserProfile.DisplayName"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCSVColumns(tt.columns)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrUnknownCSVColumn)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCSVFormatEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     CSVFormat
+		expected bool
+	}{
+		{name: "default formats", a: CSVFormat{}, b: CSVFormat{}, expected: true},
+		{name: "comma is the default delimiter", a: CSVFormat{Delimiter: ','}, b: CSVFormat{}, expected: true},
+		{name: "different delimiter", a: CSVFormat{Delimiter: ';'}, b: CSVFormat{}, expected: false},
+		{name: "different columns", a: CSVFormat{Columns: []string{"id"}}, b: CSVFormat{Columns: []string{"id", "title"}}, expected: false},
+		{name: "different quoting", a: CSVFormat{QuoteAll: true}, b: CSVFormat{}, expected: false},
+		{name: "different byte order mark", a: CSVFormat{BOM: true}, b: CSVFormat{}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.a.Equal(tt.b))
+		})
+	}
+}
+
+func TestCSVReviewWriterWithFormat(t *testing.T) {
+	review := Review{
+		ID:          42,
+		Title:       "Great",
+		Text:        `Said "wow"`,
+		CreatedDate: "2025-06-15",
+		Labels:      []string{"family", "pool"},
+		PhotoIds:    []int{7, 8, 9},
+	}
+	review.UserProfile.DisplayName = "Jane D"
+	review.UserProfile.IsVerified = true
+	review.UserProfile.Hometown.FallbackString = "Lausanne, Switzerland"
+
+	tests := []struct {
+		name     string
+		format   CSVFormat
+		expected string
+	}{
+		{
+			name:   "selected columns",
+			format: CSVFormat{Columns: []string{"id", "createdDate", "userProfile.displayName", "userProfile.isVerified", "labels", "photoCount", "userProfile.hometown.fallbackString"}},
+			expected: "Review ID,Created Date,User,User Verified,Labels,Photo Count,userProfile.hometown.fallbackString\n" +
+				"42,2025-06-15,Jane D,true,family; pool,3,\"Lausanne, Switzerland\"\n",
+		},
+		{
+			name:   "semicolon delimiter with every field quoted",
+			format: CSVFormat{Columns: []string{"id", "text"}, Delimiter: ';', QuoteAll: true},
+			expected: "\"Review ID\";\"Text\"\n" +
+				"\"42\";\"Said \"\"wow\"\"\"\n",
+		},
+		{
+			name:   "byte order mark",
+			format: CSVFormat{Columns: []string{"title"}, BOM: true},
+			expected: "\uFEFFTitle\n" +
+				"Great\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewReviewWriter("csv", &buf, nil, WithCSVFormat(tt.format))
+			assert.NoError(t, err)
+
+			assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel"}))
+			assert.NoError(t, writer.Write([]Review{review}))
+			assert.NoError(t, writer.Close())
+
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestCSVReviewWriterUnknownColumn(t *testing.T) {
+	writer, err := NewReviewWriter("csv", &bytes.Buffer{}, nil, WithCSVFormat(CSVFormat{Columns: []string{"sentiment"}}))
+	assert.ErrorIs(t, err, ErrUnknownCSVColumn)
+	assert.Nil(t, writer)
+}
+
+func TestCutoffFromFormattedCSVFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.csv")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+
+	// No Year, Month and Day columns, a semicolon delimiter and a byte order mark
+	writer, err := NewCSVReviewWriterWithFormat(file, CSVFormat{Columns: []string{"title", "text", "createdDate"}, Delimiter: ';', QuoteAll: true, BOM: true})
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel"}))
+	assert.NoError(t, writer.Write([]Review{
+		{CreatedDate: "2025-05-01", Title: "A", Text: "a; still a"},
+		{CreatedDate: "2025-04-01", Title: "B", Text: "b"},
+	}))
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, file.Close())
+
+	cutoff, err := CutoffFromFile(path)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC).Equal(cutoff.Date))
+	assert.False(t, cutoff.IsNew(Review{CreatedDate: "2025-05-01", Title: "A", Text: "a; still a"}))
+	assert.True(t, cutoff.IsNew(Review{CreatedDate: "2025-05-01", Title: "C", Text: "c"}))
+}
@@ -0,0 +1,114 @@
+package tripadvisor
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor/locations"
+)
+
+// DefaultDomain is the TripAdvisor domain requests are sent to unless a regional domain is requested
+const DefaultDomain = locations.DefaultDomain
+
+// domainLanguages maps the TripAdvisor domains, without the www. prefix, to the language of their content
+var domainLanguages = map[string]string{
+	"tripadvisor.com":       "en-US",
+	"tripadvisor.co.uk":     "en-GB",
+	"tripadvisor.ie":        "en-IE",
+	"tripadvisor.ca":        "en-CA",
+	"tripadvisor.com.au":    "en-AU",
+	"tripadvisor.co.nz":     "en-NZ",
+	"tripadvisor.in":        "en-IN",
+	"tripadvisor.com.sg":    "en-SG",
+	"tripadvisor.com.my":    "en-MY",
+	"tripadvisor.com.ph":    "en-PH",
+	"tripadvisor.co.za":     "en-ZA",
+	"en.tripadvisor.com.hk": "en-HK",
+	"tripadvisor.com.hk":    "zh-HK",
+	"tripadvisor.com.tw":    "zh-TW",
+	"tripadvisor.fr":        "fr-FR",
+	"fr.tripadvisor.ca":     "fr-CA",
+	"fr.tripadvisor.be":     "fr-BE",
+	"fr.tripadvisor.ch":     "fr-CH",
+	"tripadvisor.de":        "de-DE",
+	"tripadvisor.at":        "de-AT",
+	"tripadvisor.ch":        "de-CH",
+	"tripadvisor.it":        "it-IT",
+	"it.tripadvisor.ch":     "it-CH",
+	"tripadvisor.es":        "es-ES",
+	"tripadvisor.com.mx":    "es-MX",
+	"tripadvisor.com.ar":    "es-AR",
+	"tripadvisor.cl":        "es-CL",
+	"tripadvisor.co":        "es-CO",
+	"tripadvisor.com.pe":    "es-PE",
+	"tripadvisor.nl":        "nl-NL",
+	"tripadvisor.be":        "nl-BE",
+	"tripadvisor.pt":        "pt-PT",
+	"tripadvisor.com.br":    "pt-BR",
+	"tripadvisor.se":        "sv-SE",
+	"tripadvisor.dk":        "da-DK",
+	"no.tripadvisor.com":    "nb-NO",
+	"pl.tripadvisor.com":    "pl-PL",
+	"tripadvisor.ru":        "ru-RU",
+	"tripadvisor.com.tr":    "tr-TR",
+	"gr.tripadvisor.com":    "el-GR",
+	"tripadvisor.jp":        "ja-JP",
+	"tripadvisor.co.kr":     "ko-KR",
+	"th.tripadvisor.com":    "th-TH",
+	"tripadvisor.co.id":     "id-ID",
+	"tripadvisor.com.vn":    "vi-VN",
+	"ar.tripadvisor.com":    "ar",
+}
+
+// GetURLDomain returns the TripAdvisor domain of the URL, such as www.tripadvisor.fr.
+// It returns an empty string if the URL is not on a TripAdvisor domain.
+func GetURLDomain(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if !locations.IsTripAdvisorHost(host) {
+		return ""
+	}
+
+	return host
+}
+
+// CanonicalURL returns the canonical URL of the location of the given URL, as returned by ParseLocation.
+// A location has the same path on every TripAdvisor domain, so the canonical URL identifies it whatever domain or review page it was copied from.
+// URLs that are not TripAdvisor location URLs are returned unchanged.
+func CanonicalURL(rawURL string) string {
+	location, err := ParseLocation(rawURL, "")
+	if err != nil {
+		return rawURL
+	}
+	return location.URL
+}
+
+// GraphQLEndpoint returns the URL of the GraphQL endpoint of the given TripAdvisor domain
+func GraphQLEndpoint(domain string) string {
+	if domain == "" || domain == DefaultDomain {
+		return EndPointURL
+	}
+	return "https://" + domain + "/data/graphql/ids"
+}
+
+// AcceptLanguage returns the Accept-Language header matching the language of the given TripAdvisor domain.
+// Unknown domains fall back to US English.
+func AcceptLanguage(domain string) string {
+	tag, ok := domainLanguages[strings.TrimPrefix(domain, "www.")]
+	if !ok {
+		tag = "en-US"
+	}
+
+	language, _, _ := strings.Cut(tag, "-")
+	if language == "en" {
+		return tag + ",en;q=0.9"
+	}
+	if language == tag {
+		return tag + ",en;q=0.8"
+	}
+	return tag + "," + language + ";q=0.9,en;q=0.8"
+}
@@ -0,0 +1,151 @@
+package tripadvisor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetURLDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "default domain",
+			url:      "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected: "www.tripadvisor.com",
+		},
+		{
+			name:     "country domain",
+			url:      "https://www.tripadvisor.com.au/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected: "www.tripadvisor.com.au",
+		},
+		{
+			name:     "language subdomain",
+			url:      "https://fr.tripadvisor.ch/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected: "fr.tripadvisor.ch",
+		},
+		{
+			name:     "upper case host",
+			url:      "https://WWW.TripAdvisor.fr/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected: "www.tripadvisor.fr",
+		},
+		{
+			name:     "other domain",
+			url:      "https://www.google.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected: "",
+		},
+		{
+			name:     "invalid URL",
+			url:      "://",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GetURLDomain(tt.url))
+		})
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	assert.Equal(t,
+		"https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+		CanonicalURL("https://www.tripadvisor.co.uk/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html"),
+	)
+	assert.Equal(t,
+		"https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+		CanonicalURL("https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa"),
+	)
+	assert.Equal(t, "https://www.google.com/", CanonicalURL("https://www.google.com/"), "non TripAdvisor URLs are left untouched")
+}
+
+func TestGraphQLEndpoint(t *testing.T) {
+	assert.Equal(t, EndPointURL, GraphQLEndpoint(""))
+	assert.Equal(t, EndPointURL, GraphQLEndpoint(DefaultDomain))
+	assert.Equal(t, "https://www.tripadvisor.fr/data/graphql/ids", GraphQLEndpoint("www.tripadvisor.fr"))
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		domain   string
+		expected string
+	}{
+		{domain: "www.tripadvisor.com", expected: "en-US,en;q=0.9"},
+		{domain: "www.tripadvisor.co.uk", expected: "en-GB,en;q=0.9"},
+		{domain: "www.tripadvisor.fr", expected: "fr-FR,fr;q=0.9,en;q=0.8"},
+		{domain: "it.tripadvisor.ch", expected: "it-CH,it;q=0.9,en;q=0.8"},
+		{domain: "ar.tripadvisor.com", expected: "ar,en;q=0.8"},
+		{domain: "www.tripadvisor.xx", expected: "en-US,en;q=0.9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AcceptLanguage(tt.domain))
+		})
+	}
+}
+
+func TestMakeRequestWithOptionsDomain(t *testing.T) {
+	tests := []struct {
+		name                   string
+		domain                 string
+		expectedHost           string
+		expectedReferer        string
+		expectedAcceptLanguage string
+	}{
+		{
+			name:                   "default domain",
+			domain:                 "",
+			expectedHost:           "www.tripadvisor.com",
+			expectedReferer:        "https://www.tripadvisor.com/Hotels",
+			expectedAcceptLanguage: "en-US,en;q=0.9",
+		},
+		{
+			name:                   "regional domain",
+			domain:                 "www.tripadvisor.de",
+			expectedHost:           "www.tripadvisor.de",
+			expectedReferer:        "https://www.tripadvisor.de/Hotels",
+			expectedAcceptLanguage: "de-DE,de;q=0.9,en;q=0.8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r.Header
+				fmt.Fprint(w, `[]`)
+			}))
+			defer server.Close()
+
+			var requestedHost string
+			client := newTestClient(t, server)
+			client.Transport = hostRecorder{next: client.Transport, host: &requestedHost}
+
+			_, err := MakeRequestWithOptions(client, HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 0, ReviewLimit, RequestOptions{Domain: tt.domain})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHost, requestedHost)
+			assert.Equal(t, "https://"+tt.expectedHost, received.Get("Origin"))
+			assert.Equal(t, tt.expectedReferer, received.Get("Referer"))
+			assert.Equal(t, tt.expectedAcceptLanguage, received.Get("Accept-Language"))
+		})
+	}
+}
+
+// hostRecorder records the host a request was addressed to before it is rewritten to the test server
+type hostRecorder struct {
+	next http.RoundTripper
+	host *string
+}
+
+func (h hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	*h.host = req.URL.Host
+	return h.next.RoundTrip(req)
+}
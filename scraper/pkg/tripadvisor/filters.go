@@ -0,0 +1,99 @@
+package tripadvisor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Filter axes of a review request, besides the language
+const (
+	// FilterAxisLanguage selects the reviews written in the given languages
+	FilterAxisLanguage string = "LANGUAGE"
+
+	// FilterAxisRating selects the reviews with the given ratings, from 1 to 5
+	FilterAxisRating string = "RATING"
+
+	// FilterAxisTripType selects the reviews of the given traveler types, such as FAMILY
+	FilterAxisTripType string = "TRIP_TYPE"
+
+	// FilterAxisMonth selects the reviews of the stays in the given months, from 1 to 12
+	FilterAxisMonth string = "MONTH"
+)
+
+// TripTypes are the traveler types reviews can be filtered by
+var TripTypes = []string{"FAMILY", "COUPLES", "SOLO", "BUSINESS", "FRIENDS"}
+
+// SortOrders are the orders in which reviews can be requested
+var SortOrders = []string{SortByServerDetermined, SortByDate, SortByHelpful}
+
+// ErrInvalidFilter is returned for a filter selection TripAdvisor does not accept
+var ErrInvalidFilter = errors.New("invalid review filter")
+
+// ReviewFilters narrows down the reviews returned by TripAdvisor, on top of their language.
+// An empty field does not filter, and the selections of a field are combined with OR.
+type ReviewFilters struct {
+	// Ratings are the ratings of the reviews, from 1 to 5
+	Ratings []int `json:"ratings,omitempty"`
+
+	// TripTypes are the traveler types of the reviews, among TripTypes
+	TripTypes []string `json:"tripTypes,omitempty"`
+
+	// Months are the months of the stays, from 1 for January to 12
+	Months []int `json:"months,omitempty"`
+}
+
+// IsZero reports whether no filter is set
+func (f ReviewFilters) IsZero() bool {
+	return len(f.Ratings) == 0 && len(f.TripTypes) == 0 && len(f.Months) == 0
+}
+
+// Equal reports whether both filters select the same reviews
+func (f ReviewFilters) Equal(other ReviewFilters) bool {
+	return slices.Equal(f.Ratings, other.Ratings) && slices.Equal(f.TripTypes, other.TripTypes) && slices.Equal(f.Months, other.Months)
+}
+
+// Validate returns an error wrapping ErrInvalidFilter if a selection is out of range
+func (f ReviewFilters) Validate() error {
+	for _, rating := range f.Ratings {
+		if rating < 1 || rating > 5 {
+			return fmt.Errorf("%w: rating %d is not between 1 and 5", ErrInvalidFilter, rating)
+		}
+	}
+	for _, tripType := range f.TripTypes {
+		if !slices.Contains(TripTypes, tripType) {
+			return fmt.Errorf("%w: trip type %s is not one of %v", ErrInvalidFilter, tripType, TripTypes)
+		}
+	}
+	for _, month := range f.Months {
+		if month < 1 || month > 12 {
+			return fmt.Errorf("%w: month %d is not between 1 and 12", ErrInvalidFilter, month)
+		}
+	}
+	return nil
+}
+
+// Filters returns the filter axes of the request, starting with the given languages
+func (f ReviewFilters) Filters(languages []string) Filters {
+	filters := Filters{languageFilter(languages)}
+	if len(f.Ratings) > 0 {
+		filters = append(filters, Filter{Axis: FilterAxisRating, Selections: itoaAll(f.Ratings)})
+	}
+	if len(f.TripTypes) > 0 {
+		filters = append(filters, Filter{Axis: FilterAxisTripType, Selections: f.TripTypes})
+	}
+	if len(f.Months) > 0 {
+		filters = append(filters, Filter{Axis: FilterAxisMonth, Selections: itoaAll(f.Months)})
+	}
+	return filters
+}
+
+// itoaAll formats the numbers as the selections of a filter
+func itoaAll(numbers []int) []string {
+	selections := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		selections = append(selections, strconv.Itoa(n))
+	}
+	return selections
+}
@@ -0,0 +1,89 @@
+package tripadvisor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewFiltersFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  ReviewFilters
+		expected Filters
+	}{
+		{
+			name:     "languages only",
+			filters:  ReviewFilters{},
+			expected: Filters{{Axis: FilterAxisLanguage, Selections: []string{"en", "fr"}}},
+		},
+		{
+			name:    "every axis",
+			filters: ReviewFilters{Ratings: []int{1, 2}, TripTypes: []string{"BUSINESS"}, Months: []int{12, 1, 2}},
+			expected: Filters{
+				{Axis: FilterAxisLanguage, Selections: []string{"en", "fr"}},
+				{Axis: FilterAxisRating, Selections: []string{"1", "2"}},
+				{Axis: FilterAxisTripType, Selections: []string{"BUSINESS"}},
+				{Axis: FilterAxisMonth, Selections: []string{"12", "1", "2"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filters.Filters([]string{"en", "fr"}))
+		})
+	}
+}
+
+func TestReviewFiltersValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		filters     ReviewFilters
+		expectError bool
+	}{
+		{name: "no filters", filters: ReviewFilters{}},
+		{name: "valid filters", filters: ReviewFilters{Ratings: []int{1, 5}, TripTypes: []string{"FAMILY", "SOLO"}, Months: []int{1, 12}}},
+		{name: "rating out of range", filters: ReviewFilters{Ratings: []int{6}}, expectError: true},
+		{name: "unknown trip type", filters: ReviewFilters{TripTypes: []string{"PETS"}}, expectError: true},
+		{name: "month out of range", filters: ReviewFilters{Months: []int{0}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filters.Validate()
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidFilter)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientCountReviewsFiltered(t *testing.T) {
+	var requests []Variables
+	server := newGraphQLServer(t, func(batch []graphQLRequest) reviewListPage {
+		requests = append(requests, batch[0].Variables.Variables)
+		return reviewListPage{}
+	})
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+	opts := ReviewsOptions{
+		Languages:    []string{"en"},
+		OriginalText: true,
+		Request:      RequestOptions{SortBy: SortByHelpful, Filters: ReviewFilters{Ratings: []int{1, 2}}},
+	}
+	count, err := client.CountReviews(context.Background(), &Location{Type: LocationTypeHotel, GeoID: 1, LocationID: 1}, opts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count, "no matching reviews is not an error")
+	assert.Len(t, requests, 1, "the original text is not fetched to count the reviews")
+	assert.Equal(t, SortByHelpful, requests[0].SortBy)
+	assert.Equal(t, Filters{
+		{Axis: FilterAxisLanguage, Selections: []string{"en"}},
+		{Axis: FilterAxisRating, Selections: []string{"1", "2"}},
+	}, requests[0].Filters)
+}
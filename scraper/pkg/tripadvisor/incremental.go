@@ -0,0 +1,368 @@
+package tripadvisor
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+)
+
+// createdDateLayout is the layout of Review.CreatedDate
+const createdDateLayout = "2006-01-02"
+
+// Cutoff separates the reviews that are new since a previous scrape from the ones that were already scraped.
+// Reviews created on the cutoff date are new unless they were part of the previous scrape.
+type Cutoff struct {
+	Date time.Time
+
+	// known holds the keys of the previously scraped reviews created on the cutoff date
+	known map[string]struct{}
+}
+
+// NewCutoff returns a Cutoff treating every review created on or after the given date as new
+func NewCutoff(date time.Time) *Cutoff {
+	return &Cutoff{
+		Date:  date.Truncate(24 * time.Hour),
+		known: make(map[string]struct{}),
+	}
+}
+
+// ParseCutoffDate parses a cutoff date in the format of Review.CreatedDate (YYYY-MM-DD)
+func ParseCutoffDate(value string) (*Cutoff, error) {
+	date, err := time.Parse(createdDateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cutoff date %q: %w", value, err)
+	}
+	return NewCutoff(date), nil
+}
+
+// CutoffFromFile reads the output of a previous scrape and returns a Cutoff at its newest review.
+// The file type is detected from the extension (.csv, .json, .ndjson, .parquet or .xlsx).
+func CutoffFromFile(path string) (*Cutoff, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening previous output: %w", err)
+	}
+	defer file.Close()
+
+	cutoff := &Cutoff{known: make(map[string]struct{})}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		err = cutoff.observeCSV(file)
+	case ".json":
+		err = cutoff.observeJSON(file)
+	case ".ndjson":
+		err = cutoff.observeNDJSON(file)
+	case ".parquet":
+		err = cutoff.observeParquet(file)
+	case ".xlsx":
+		err = cutoff.observeXLSX(file)
+	default:
+		return nil, fmt.Errorf("unsupported previous output %s. Use a csv, json, ndjson, parquet or xlsx file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading previous output %s: %w", path, err)
+	}
+
+	if cutoff.Date.IsZero() {
+		return nil, fmt.Errorf("no reviews found in previous output %s", path)
+	}
+
+	return cutoff, nil
+}
+
+// IsNew reports whether the review was not part of the previous scrape
+func (c *Cutoff) IsNew(r Review) bool {
+	date, err := time.Parse(createdDateLayout, r.CreatedDate)
+	if err != nil {
+		// Keep reviews with an unknown date rather than silently dropping them
+		return true
+	}
+
+	if date.Before(c.Date) {
+		return false
+	}
+
+	if date.Equal(c.Date) {
+		_, known := c.known[reviewKey(r.Title, r.Text)]
+		return !known
+	}
+
+	return true
+}
+
+// FilterNew returns the reviews of a page that are new.
+// done is true when every review on the page is older than the cutoff date, meaning no later page can contain new reviews.
+func (c *Cutoff) FilterNew(reviews []Review) (newReviews []Review, done bool) {
+	done = len(reviews) > 0
+	for _, r := range reviews {
+		if c.IsNew(r) {
+			newReviews = append(newReviews, r)
+		}
+
+		date, err := time.Parse(createdDateLayout, r.CreatedDate)
+		if err != nil || !date.Before(c.Date) {
+			done = false
+		}
+	}
+	return newReviews, done
+}
+
+// observe records a previously scraped review
+func (c *Cutoff) observe(date time.Time, title string, text string) {
+	switch {
+	case date.After(c.Date):
+		c.Date = date
+		c.known = map[string]struct{}{reviewKey(title, text): {}}
+	case date.Equal(c.Date):
+		c.known[reviewKey(title, text)] = struct{}{}
+	}
+}
+
+// observeCSV records the reviews of a CSV file written by a CSVReviewWriter.
+// The delimiter is detected from the header, and the creation date is read either from the Created Date column or from the Year, Month and Day columns.
+func (c *Cutoff) observeCSV(r io.Reader) error {
+	buffered := bufio.NewReader(r)
+	headerLine, err := buffered.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error reading csv header: %w", err)
+	}
+	headerLine = strings.TrimPrefix(headerLine, "\uFEFF")
+
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(headerLine), buffered))
+	reader.Comma = sniffCSVDelimiter(headerLine)
+	reader.FieldsPerRecord = -1
+
+	headers, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("error reading csv header: %w", err)
+	}
+
+	// The title and text only tell apart the reviews created on the cutoff date, so they are optional
+	column := func(row []string, name string) string {
+		index := slices.Index(headers, name)
+		if index < 0 || index >= len(row) {
+			return ""
+		}
+		return row[index]
+	}
+
+	createdDate := func(row []string) string {
+		return column(row, "Created Date")
+	}
+	if !slices.Contains(headers, "Created Date") {
+		for _, name := range []string{"Year", "Month", "Day"} {
+			if !slices.Contains(headers, name) {
+				return fmt.Errorf("missing %q or %q column", "Created Date", name)
+			}
+		}
+		createdDate = func(row []string) string {
+			return fmt.Sprintf("%s-%s-%s", column(row, "Year"), column(row, "Month"), column(row, "Day"))
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading csv row: %w", err)
+		}
+
+		date, err := time.Parse(createdDateLayout, substring(createdDate(row), 0, len(createdDateLayout)))
+		if err != nil {
+			continue
+		}
+		c.observe(date, column(row, "Title"), column(row, "Text"))
+	}
+}
+
+// sniffCSVDelimiter returns the delimiter among the ones commonly used that occurs the most in the header line
+func sniffCSVDelimiter(headerLine string) rune {
+	delimiter := ','
+	for _, candidate := range []rune{';', '\t', '|'} {
+		if strings.Count(headerLine, string(candidate)) > strings.Count(headerLine, string(delimiter)) {
+			delimiter = candidate
+		}
+	}
+	return delimiter
+}
+
+// observeJSON records the reviews of a JSON file holding a ScrapeResult, one review at a time
+func (c *Cutoff) observeJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("error reading json: %w", err)
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("error reading json: %w", err)
+		}
+
+		// Skip everything but the reviews array
+		if key != "reviews" {
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return fmt.Errorf("error reading json: %w", err)
+			}
+			continue
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("error reading reviews: %w", err)
+		}
+		for decoder.More() {
+			var review Review
+			if err := decoder.Decode(&review); err != nil {
+				return fmt.Errorf("error reading review: %w", err)
+			}
+			if date, err := time.Parse(createdDateLayout, review.CreatedDate); err == nil {
+				c.observe(date, review.Title, review.Text)
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("error reading reviews: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reviewKey identifies a review by its content, as CSV outputs do not contain review IDs
+func reviewKey(title string, text string) string {
+	return title + "\x00" + text
+}
+
+// observeNDJSON records the reviews of an NDJSON file, one line at a time. The metadata line, if any, is skipped.
+func (c *Cutoff) observeNDJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+
+	for {
+		var line json.RawMessage
+		err := decoder.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading review: %w", err)
+		}
+
+		// The keys are checked before decoding a Review, which would ignore the metadata key
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(line, &fields); err != nil {
+			return fmt.Errorf("error reading review: %w", err)
+		}
+		if _, ok := fields["_metadata"]; ok {
+			continue
+		}
+
+		var review Review
+		if err := json.Unmarshal(line, &review); err != nil {
+			return fmt.Errorf("error reading review: %w", err)
+		}
+		if date, err := time.Parse(createdDateLayout, review.CreatedDate); err == nil {
+			c.observe(date, review.Title, review.Text)
+		}
+	}
+}
+
+// observeParquet records the reviews of a Parquet file written by ParquetReviewWriter, one row group at a time
+func (c *Cutoff) observeParquet(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading parquet: %w", err)
+	}
+
+	parquetFile, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		return fmt.Errorf("error reading parquet: %w", err)
+	}
+
+	reader := parquet.NewGenericReader[ParquetReview](parquetFile)
+	defer reader.Close()
+
+	rows := make([]ParquetReview, ReviewLimit)
+	for {
+		n, err := reader.Read(rows)
+		for _, row := range rows[:n] {
+			if row.CreatedDate != nil {
+				c.observe(*row.CreatedDate, row.Title, row.Text)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading parquet row: %w", err)
+		}
+	}
+}
+
+// observeXLSX records the reviews of the reviews sheet of an XLSX workbook, one row at a time
+func (c *Cutoff) observeXLSX(r io.Reader) error {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return fmt.Errorf("error reading xlsx: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := file.Rows(XLSXReviewsSheet)
+	if err != nil {
+		return fmt.Errorf("error reading xlsx sheet %s: %w", XLSXReviewsSheet, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return fmt.Errorf("missing xlsx header")
+	}
+	headers, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("error reading xlsx header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for _, name := range []string{"Title", "Text", "Created Date"} {
+		index := slices.Index(headers, name)
+		if index < 0 {
+			return fmt.Errorf("missing %q column", name)
+		}
+		columns[name] = index
+	}
+
+	for rows.Next() {
+		row, err := rows.Columns()
+		if err != nil {
+			return fmt.Errorf("error reading xlsx row: %w", err)
+		}
+
+		// Trailing empty cells are not returned
+		cell := func(name string) string {
+			if columns[name] < len(row) {
+				return row[columns[name]]
+			}
+			return ""
+		}
+
+		date, err := time.Parse(createdDateLayout, cell("Created Date"))
+		if err != nil {
+			continue
+		}
+		c.observe(date, cell("Title"), cell("Text"))
+	}
+	return rows.Error()
+}
@@ -0,0 +1,214 @@
+package tripadvisor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCutoffDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{
+			name:     "valid date",
+			value:    "2025-01-31",
+			expected: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "invalid format",
+			value:       "31/01/2025",
+			expectError: true,
+		},
+		{
+			name:        "empty value",
+			value:       "",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cutoff, err := ParseCutoffDate(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cutoff.Date)
+		})
+	}
+}
+
+func TestCutoffFilterNew(t *testing.T) {
+	cutoff, err := ParseCutoffDate("2025-03-10")
+	assert.NoError(t, err)
+	cutoff.known[reviewKey("Seen", "Already scraped")] = struct{}{}
+
+	tests := []struct {
+		name         string
+		reviews      []Review
+		expectedIDs  []int
+		expectedDone bool
+	}{
+		{
+			name: "all reviews newer than the cutoff",
+			reviews: []Review{
+				{ID: 1, CreatedDate: "2025-04-01"},
+				{ID: 2, CreatedDate: "2025-03-11"},
+			},
+			expectedIDs:  []int{1, 2},
+			expectedDone: false,
+		},
+		{
+			name: "page crossing the cutoff",
+			reviews: []Review{
+				{ID: 1, CreatedDate: "2025-03-11"},
+				{ID: 2, CreatedDate: "2025-03-10", Title: "Seen", Text: "Already scraped"},
+				{ID: 3, CreatedDate: "2025-03-10", Title: "Unseen", Text: "Posted later that day"},
+				{ID: 4, CreatedDate: "2025-03-09"},
+			},
+			expectedIDs:  []int{1, 3},
+			expectedDone: false,
+		},
+		{
+			name: "all reviews older than the cutoff",
+			reviews: []Review{
+				{ID: 1, CreatedDate: "2025-03-09"},
+				{ID: 2, CreatedDate: "2024-12-31"},
+			},
+			expectedIDs:  nil,
+			expectedDone: true,
+		},
+		{
+			name: "reviews with an unknown date are kept",
+			reviews: []Review{
+				{ID: 1, CreatedDate: ""},
+				{ID: 2, CreatedDate: "2024-12-31"},
+			},
+			expectedIDs:  []int{1},
+			expectedDone: false,
+		},
+		{
+			name:         "empty page",
+			reviews:      nil,
+			expectedIDs:  nil,
+			expectedDone: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newReviews, done := cutoff.FilterNew(tt.reviews)
+
+			var ids []int
+			for _, r := range newReviews {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedDone, done)
+		})
+	}
+}
+
+func TestCutoffFromFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileName     string
+		content      string
+		expectedDate time.Time
+		expectedNew  []Review
+		expectedOld  []Review
+		expectError  bool
+	}{
+		{
+			name:     "csv output",
+			fileName: "reviews.csv",
+			content: "Location Name,Title,Text,Rating,Year,Month,Day,Trip Type,Stay Date\n" +
+				"Test_Hotel,Older,Fine,4,2025,01,02,,\n" +
+				"Test_Hotel,Newest,\"Great, really\",5,2025,02,14,,\n" +
+				"Test_Hotel,Same day,Good,4,2025,02,14,,\n",
+			expectedDate: time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC),
+			expectedNew:  []Review{{CreatedDate: "2025-02-14", Title: "Another", Text: "Posted later"}},
+			expectedOld:  []Review{{CreatedDate: "2025-02-14", Title: "Newest", Text: "Great, really"}, {CreatedDate: "2025-01-20"}},
+		},
+		{
+			name:     "json output",
+			fileName: "reviews.json",
+			content: `{"reviews":[{"id":1,"createdDate":"2025-05-01","title":"A","text":"a"},` +
+				`{"id":2,"createdDate":"2025-04-01","title":"B","text":"b"}],"michelin":{"awardHeader":"MICHELIN Guide"}}`,
+			expectedDate: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			expectedNew:  []Review{{CreatedDate: "2025-05-02"}},
+			expectedOld:  []Review{{CreatedDate: "2025-05-01", Title: "A", Text: "a"}},
+		},
+		{
+			name:     "ndjson output with a metadata line",
+			fileName: "reviews.ndjson",
+			content: `{"id":1,"createdDate":"2025-05-01","title":"A","text":"a"}` + "\n" +
+				`{"id":2,"createdDate":"2025-04-01","title":"B","text":"b"}` + "\n" +
+				`{"_metadata":{"locationName":"Test_Hotel","reviewCount":2,"partial":false}}` + "\n",
+			expectedDate: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			expectedNew:  []Review{{CreatedDate: "2025-05-02"}},
+			expectedOld:  []Review{{CreatedDate: "2025-05-01", Title: "A", Text: "a"}},
+		},
+		{
+			name:     "ndjson metadata line with a date-like field is skipped",
+			fileName: "reviews.ndjson",
+			content: `{"id":1,"createdDate":"2025-05-01","title":"A","text":"a"}` + "\n" +
+				`{"createdDate":"2025-09-30","_metadata":{"locationName":"Test_Hotel","reviewCount":1,"partial":false}}` + "\n",
+			expectedDate: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			expectedNew:  []Review{{CreatedDate: "2025-05-02"}},
+			expectedOld:  []Review{{CreatedDate: "2025-05-01", Title: "A", Text: "a"}},
+		},
+		{
+			name:        "csv without date columns",
+			fileName:    "reviews.csv",
+			content:     "Title,Text\nA,a\n",
+			expectError: true,
+		},
+		{
+			name:        "empty json output",
+			fileName:    "reviews.json",
+			content:     `{"reviews":[]}`,
+			expectError: true,
+		},
+		{
+			name:        "unsupported extension",
+			fileName:    "reviews.xml",
+			content:     "<reviews/>",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			cutoff, err := CutoffFromFile(path)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDate, cutoff.Date)
+			for _, r := range tt.expectedNew {
+				assert.True(t, cutoff.IsNew(r), "%+v should be new", r)
+			}
+			for _, r := range tt.expectedOld {
+				assert.False(t, cutoff.IsNew(r), "%+v should not be new", r)
+			}
+		})
+	}
+}
+
+func TestCutoffFromMissingFile(t *testing.T) {
+	_, err := CutoffFromFile(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
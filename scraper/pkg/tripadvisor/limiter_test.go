@@ -0,0 +1,70 @@
+package tripadvisor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		burst             int
+		expectNil         bool
+	}{
+		{
+			name:              "zero rate disables the limiter",
+			requestsPerSecond: 0,
+			expectNil:         true,
+		},
+		{
+			name:              "negative rate disables the limiter",
+			requestsPerSecond: -1,
+			expectNil:         true,
+		},
+		{
+			name:              "positive rate creates a limiter",
+			requestsPerSecond: 2,
+			burst:             3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.requestsPerSecond, tt.burst)
+			if tt.expectNil {
+				assert.Nil(t, limiter)
+				assert.NoError(t, limiter.Wait(context.Background()))
+				return
+			}
+			assert.NotNil(t, limiter)
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	// A burst of 2 at 20 requests per second: the first 2 calls pass immediately and the next 2 wait 50ms each
+	limiter := NewRateLimiter(20, 2)
+
+	start := time.Now()
+	for range 4 {
+		assert.NoError(t, limiter.Wait(context.Background()))
+	}
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 90*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,15 @@
+package tripadvisor
+
+import "github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor/locations"
+
+// ErrInvalidLocation is returned when a location URL or ID can not be parsed
+var ErrInvalidLocation = locations.ErrInvalidLocation
+
+// Location is a TripAdvisor location parsed from a URL or an ID
+type Location = locations.Location
+
+// ParseLocation parses a TripAdvisor location from a review page URL or from a bare location ID such as d231860.
+// See locations.Parse.
+func ParseLocation(input string, locationType LocationType) (*Location, error) {
+	return locations.Parse(input, locationType)
+}
@@ -0,0 +1,18 @@
+package locations
+
+import "regexp"
+
+// DefaultDomain is the TripAdvisor domain requests are sent to unless a regional domain is requested
+const DefaultDomain = "www.tripadvisor.com"
+
+// hostPattern matches www.tripadvisor.com, the mobile m.tripadvisor.com and the country domains of TripAdvisor,
+// such as www.tripadvisor.fr, www.tripadvisor.co.uk, www.tripadvisor.com.au or fr.tripadvisor.ch
+const hostPattern = `(?:(?:www|m|[a-z]{2})\.)?tripadvisor\.(?:com(?:\.[a-z]{2})?|co\.[a-z]{2}|[a-z]{2})`
+
+// hostRegexp matches a TripAdvisor host
+var hostRegexp = regexp.MustCompile(`^` + hostPattern + `$`)
+
+// IsTripAdvisorHost reports whether the lower case host is a TripAdvisor domain
+func IsTripAdvisorHost(host string) bool {
+	return hostRegexp.MatchString(host)
+}
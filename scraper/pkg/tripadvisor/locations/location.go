@@ -0,0 +1,198 @@
+package locations
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLocation is returned when a location URL or ID can not be parsed
+var ErrInvalidLocation = errors.New("invalid location")
+
+// bareLocationIDRegexp matches a location ID given on its own, such as d231860 or 231860
+var bareLocationIDRegexp = regexp.MustCompile(`^d?\d+$`)
+
+// paginationRegexp matches the pagination segment of a review page URL, such as or40
+var paginationRegexp = regexp.MustCompile(`^or\d+$`)
+
+// Location is a TripAdvisor location parsed from a URL or an ID
+type Location struct {
+	// Type is the location type, such as TypeHotel
+	Type Type
+
+	// GeoID is the ID of the geographic area of the location. It is 0 for types that are not geo-scoped, such as airlines, and for locations given by ID.
+	GeoID uint32
+
+	// LocationID is the ID of the location
+	LocationID uint32
+
+	// Slug is the part of the URL after the IDs, such as Beau_Rivage_Palace-Lausanne_Canton_of_Vaud
+	Slug string
+
+	// Name is the name of the location derived from the slug, such as Beau_Rivage_Palace
+	Name string
+
+	// Domain is the TripAdvisor domain of the URL, such as www.tripadvisor.fr. The mobile domain is reported as www.tripadvisor.com.
+	// It is empty for locations given by ID.
+	Domain string
+
+	// URL is the canonical URL of the first review page of the location on DefaultDomain.
+	// It is empty for locations given by ID.
+	URL string
+}
+
+// Parse parses a TripAdvisor location from a review page URL or from a bare location ID such as d231860.
+// URLs may be on any TripAdvisor domain, including the mobile one, and may point to any review page or carry a query string or fragment.
+// locationType is required for bare IDs. For URLs it is optional, but when set, it must match the type of the URL.
+func Parse(input string, locationType Type) (*Location, error) {
+	input = strings.TrimSpace(input)
+
+	if bareLocationIDRegexp.MatchString(input) {
+		return parseLocationID(input, locationType)
+	}
+
+	location, err := parseLocationURL(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if locationType != "" && locationType != location.Type {
+		return nil, fmt.Errorf("%w: %s is a %s URL, not a %s URL", ErrInvalidLocation, input, location.Type, locationType)
+	}
+
+	return location, nil
+}
+
+// parseLocationID parses a bare location ID of the given type
+func parseLocationID(input string, locationType Type) (*Location, error) {
+	if locationType == "" {
+		return nil, fmt.Errorf("%w: the location type is required for location ID %s", ErrInvalidLocation, input)
+	}
+	if _, err := Lookup(locationType); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLocation, err)
+	}
+
+	locationID, err := parseID(strings.TrimPrefix(input, "d"), "location")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Location{
+		Type:       locationType,
+		LocationID: locationID,
+		Name:       fmt.Sprintf("d%d", locationID),
+	}, nil
+}
+
+// parseLocationURL parses a review page URL
+func parseLocationURL(rawURL string) (*Location, error) {
+	// Sample hotel url: https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html
+	// Sample second page: https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-or10-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html#REVIEWS
+	// Sample airline url: https://www.tripadvisor.com/Airline_Review-d8728979-Reviews-Pegasus-Airlines
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLocation, err)
+	}
+
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return nil, fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidLocation, rawURL)
+	}
+
+	domain := strings.ToLower(parsed.Hostname())
+	if !IsTripAdvisorHost(domain) {
+		return nil, fmt.Errorf("%w: %s is not a TripAdvisor domain", ErrInvalidLocation, parsed.Host)
+	}
+
+	path := strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".html")
+	page, rest, _ := strings.Cut(path, "-")
+
+	descriptor, ok := typeForPage(page)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not the review page of a known location type", ErrInvalidLocation, parsed.Path)
+	}
+
+	location := &Location{
+		Type:   descriptor.Type,
+		Domain: domain,
+	}
+	if domain == "m.tripadvisor.com" {
+		location.Domain = DefaultDomain
+	}
+
+	segments := strings.Split(rest, "-")
+
+	// Geo-scoped types have a geo ID before the location ID
+	if descriptor.GeoScoped {
+		if len(segments) == 0 || !strings.HasPrefix(segments[0], "g") {
+			return nil, fmt.Errorf("%w: missing geo ID in %s", ErrInvalidLocation, parsed.Path)
+		}
+		location.GeoID, err = parseID(strings.TrimPrefix(segments[0], "g"), "geo")
+		if err != nil {
+			return nil, err
+		}
+		segments = segments[1:]
+	}
+
+	if len(segments) == 0 || !strings.HasPrefix(segments[0], "d") {
+		return nil, fmt.Errorf("%w: missing location ID in %s", ErrInvalidLocation, parsed.Path)
+	}
+	location.LocationID, err = parseID(strings.TrimPrefix(segments[0], "d"), "location")
+	if err != nil {
+		return nil, err
+	}
+	segments = segments[1:]
+
+	if len(segments) == 0 || segments[0] != "Reviews" {
+		return nil, fmt.Errorf("%w: %s is not a review page", ErrInvalidLocation, parsed.Path)
+	}
+	segments = segments[1:]
+
+	// Skip the pagination of URLs copied from a later review page
+	if len(segments) > 0 && paginationRegexp.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+
+	location.Slug = strings.Join(segments, "-")
+	if location.Slug == "" {
+		return nil, fmt.Errorf("%w: missing location name in %s", ErrInvalidLocation, parsed.Path)
+	}
+
+	// Geo-scoped types, such as hotels, append the geographic area to the name, others, such as airlines, do not
+	if descriptor.GeoScoped {
+		location.Name = segments[0]
+	} else {
+		location.Name = strings.Join(segments, "_")
+	}
+
+	location.URL = canonicalLocationURL(location, descriptor)
+
+	return location, nil
+}
+
+// canonicalLocationURL returns the URL of the first review page of the location on DefaultDomain
+func canonicalLocationURL(location *Location, descriptor *Descriptor) string {
+	ids := fmt.Sprintf("d%d", location.LocationID)
+	if descriptor.GeoScoped {
+		ids = fmt.Sprintf("g%d-%s", location.GeoID, ids)
+	}
+
+	canonical := fmt.Sprintf("https://%s/%s-%s-Reviews-%s", DefaultDomain, descriptor.PageName, ids, location.Slug)
+	if descriptor.GeoScoped {
+		canonical += ".html"
+	}
+
+	return canonical
+}
+
+// parseID parses a numeric TripAdvisor ID
+func parseID(value string, kind string) (uint32, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: invalid %s ID %q", ErrInvalidLocation, kind, value)
+	}
+	return uint32(id), nil
+}
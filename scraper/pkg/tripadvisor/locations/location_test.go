@@ -0,0 +1,162 @@
+package locations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		locationType Type
+		expected     *Location
+		expectError  bool
+	}{
+		{
+			name:  "hotel URL",
+			input: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+			expected: &Location{
+				Type:       "HOTEL",
+				GeoID:      188107,
+				LocationID: 231860,
+				Slug:       "Beau_Rivage_Palace-Lausanne_Canton_of_Vaud",
+				Name:       "Beau_Rivage_Palace",
+				Domain:     "www.tripadvisor.com",
+				URL:        "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+			},
+		},
+		{
+			name:  "paginated restaurant URL with query string and fragment",
+			input: "https://www.tripadvisor.fr/Restaurant_Review-g187265-d11827759-Reviews-or40-La_Terrasse-Lyon_Rhone_Auvergne_Rhone_Alpes.html?filterLang=fr#REVIEWS",
+			expected: &Location{
+				Type:       "RESTO",
+				GeoID:      187265,
+				LocationID: 11827759,
+				Slug:       "La_Terrasse-Lyon_Rhone_Auvergne_Rhone_Alpes",
+				Name:       "La_Terrasse",
+				Domain:     "www.tripadvisor.fr",
+				URL:        "https://www.tripadvisor.com/Restaurant_Review-g187265-d11827759-Reviews-La_Terrasse-Lyon_Rhone_Auvergne_Rhone_Alpes.html",
+			},
+		},
+		{
+			name:  "mobile attraction URL",
+			input: "https://m.tripadvisor.com/Attraction_Review-g187261-d1008501-Reviews-Les_Ailes_du_Mont_Blanc-Chamonix_Haute_Savoie_Auvergne_Rhone_Alpes.html",
+			expected: &Location{
+				Type:       "ATTRACTION",
+				GeoID:      187261,
+				LocationID: 1008501,
+				Slug:       "Les_Ailes_du_Mont_Blanc-Chamonix_Haute_Savoie_Auvergne_Rhone_Alpes",
+				Name:       "Les_Ailes_du_Mont_Blanc",
+				Domain:     "www.tripadvisor.com",
+				URL:        "https://www.tripadvisor.com/Attraction_Review-g187261-d1008501-Reviews-Les_Ailes_du_Mont_Blanc-Chamonix_Haute_Savoie_Auvergne_Rhone_Alpes.html",
+			},
+		},
+		{
+			name:  "paginated airline URL with hyphenated name",
+			input: "https://www.tripadvisor.co.uk/Airline_Review-d8728979-Reviews-or5-Pegasus-Airlines/",
+			expected: &Location{
+				Type:       "AIRLINE",
+				LocationID: 8728979,
+				Slug:       "Pegasus-Airlines",
+				Name:       "Pegasus_Airlines",
+				Domain:     "www.tripadvisor.co.uk",
+				URL:        "https://www.tripadvisor.com/Airline_Review-d8728979-Reviews-Pegasus-Airlines",
+			},
+		},
+		{
+			name:         "URL with the matching type",
+			input:        "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			locationType: "AIRLINE",
+			expected: &Location{
+				Type:       "AIRLINE",
+				LocationID: 8729113,
+				Slug:       "Lufthansa",
+				Name:       "Lufthansa",
+				Domain:     "www.tripadvisor.com",
+				URL:        "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			},
+		},
+		{
+			name:         "bare location ID",
+			input:        "d231860",
+			locationType: "HOTEL",
+			expected: &Location{
+				Type:       "HOTEL",
+				LocationID: 231860,
+				Name:       "d231860",
+			},
+		},
+		{
+			name:        "bare location ID without type",
+			input:       "d231860",
+			expectError: true,
+		},
+		{
+			name:         "bare location ID with unknown type",
+			input:        "231860",
+			locationType: "CRUISE",
+			expectError:  true,
+		},
+		{
+			name:         "URL with another type",
+			input:        "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			locationType: "HOTEL",
+			expectError:  true,
+		},
+		{
+			name:        "non TripAdvisor domain",
+			input:       "https://www.example.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace.html",
+			expectError: true,
+		},
+		{
+			name:        "unsupported page",
+			input:       "https://www.tripadvisor.com/Tourism-g188107-Lausanne_Canton_of_Vaud-Vacations.html",
+			expectError: true,
+		},
+		{
+			name:        "missing geo ID",
+			input:       "https://www.tripadvisor.com/Hotel_Review-d231860-Reviews-Beau_Rivage_Palace.html",
+			expectError: true,
+		},
+		{
+			name:        "truncated URL",
+			input:       "https://www.tripadvisor.com/Hotel_Review-g188107",
+			expectError: true,
+		},
+		{
+			name:        "not a review page",
+			input:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Beau_Rivage_Palace.html",
+			expectError: true,
+		},
+		{
+			name:        "missing name",
+			input:       "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews.html",
+			expectError: true,
+		},
+		{
+			name:        "location ID overflowing 32 bits",
+			input:       "https://www.tripadvisor.com/Hotel_Review-g188107-d99999999999-Reviews-Beau_Rivage_Palace.html",
+			expectError: true,
+		},
+		{
+			name:        "empty input",
+			input:       "",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := Parse(tt.input, tt.locationType)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidLocation)
+				assert.Nil(t, location)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, location)
+		})
+	}
+}
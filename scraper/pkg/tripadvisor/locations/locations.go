@@ -0,0 +1,133 @@
+// Package locations parses TripAdvisor location URLs and holds the registry of the location types they belong to.
+// It has no dependency besides the standard library, so it can be imported to validate URLs without pulling in the scraper.
+package locations
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownType is returned when a location type is not registered
+var ErrUnknownType = errors.New("unknown location type")
+
+// Type is the kind of a TripAdvisor location, such as a hotel or an airline
+type Type string
+
+// The location types registered by default
+const (
+	TypeHotel      Type = "HOTEL"
+	TypeRestaurant Type = "RESTO"
+	TypeAirline    Type = "AIRLINE"
+	TypeAttraction Type = "ATTRACTION"
+)
+
+// Descriptor describes how the locations of a type are found in URLs
+type Descriptor struct {
+	// Type is the location type
+	Type Type
+
+	// Label is the human readable name of the type, such as Restaurant
+	Label string
+
+	// PageName is the page name used in the review page URLs and in the route requests, such as Hotel_Review
+	PageName string
+
+	// GeoScoped is true when the locations belong to a geographic area. Their URLs then carry a geo ID,
+	// end with .html and append the area to the location name.
+	GeoScoped bool
+
+	// SortableByDate is true when the reviews can be requested newest first
+	SortableByDate bool
+
+	// URLRegexp matches the canonical review page URLs of the type on any TripAdvisor domain
+	URLRegexp *regexp.Regexp
+}
+
+var (
+	typesMu sync.RWMutex
+	types   = map[Type]*Descriptor{}
+
+	// typeOrder keeps the registration order so that Types is deterministic
+	typeOrder []Type
+)
+
+func init() {
+	Register(&Descriptor{Type: TypeHotel, Label: "Hotel", PageName: "Hotel_Review", GeoScoped: true, SortableByDate: true})
+	Register(&Descriptor{Type: TypeRestaurant, Label: "Restaurant", PageName: "Restaurant_Review", GeoScoped: true, SortableByDate: true})
+	Register(&Descriptor{Type: TypeAirline, Label: "Airline", PageName: "Airline_Review"})
+	Register(&Descriptor{Type: TypeAttraction, Label: "Attraction", PageName: "Attraction_Review", GeoScoped: true, SortableByDate: true})
+}
+
+// Register registers a location type, replacing any descriptor previously registered for the same type.
+// The URL regexp is derived from the page name when it is not set.
+func Register(descriptor *Descriptor) {
+	if descriptor.URLRegexp == nil {
+		descriptor.URLRegexp = urlRegexp(descriptor.PageName, descriptor.GeoScoped)
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	if _, ok := types[descriptor.Type]; !ok {
+		typeOrder = append(typeOrder, descriptor.Type)
+	}
+	types[descriptor.Type] = descriptor
+}
+
+// Lookup returns the descriptor of the given location type
+func Lookup(locationType Type) (*Descriptor, error) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	descriptor, ok := types[locationType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, locationType)
+	}
+	return descriptor, nil
+}
+
+// Types returns the registered location types in registration order
+func Types() []Type {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	return append([]Type(nil), typeOrder...)
+}
+
+// ParseType parses a registered location type, ignoring case
+func ParseType(value string) (Type, error) {
+	locationType := Type(strings.ToUpper(strings.TrimSpace(value)))
+	if _, err := Lookup(locationType); err != nil {
+		return "", err
+	}
+	return locationType, nil
+}
+
+// String returns the location type as used in the configuration, such as HOTEL
+func (t Type) String() string {
+	return string(t)
+}
+
+// typeForPage returns the descriptor of the location type whose review pages have the given page name
+func typeForPage(pageName string) (*Descriptor, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	for _, descriptor := range types {
+		if descriptor.PageName == pageName {
+			return descriptor, true
+		}
+	}
+	return nil, false
+}
+
+// urlRegexp returns the regexp matching the canonical review page URLs with the given page name
+func urlRegexp(pageName string, geoScoped bool) *regexp.Regexp {
+	if geoScoped {
+		return regexp.MustCompile(`^https:\/\/` + hostPattern + `\/` + regexp.QuoteMeta(pageName) + `-g\d{1,10}-d\d{1,10}-Reviews-[\w-]{1,255}\.html$`)
+	}
+	return regexp.MustCompile(`^https:\/\/` + hostPattern + `\/` + regexp.QuoteMeta(pageName) + `-d\d{1,10}-Reviews-[\w-]{1,255}$`)
+}
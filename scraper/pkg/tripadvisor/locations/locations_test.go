@@ -0,0 +1,48 @@
+package locations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypes(t *testing.T) {
+	assert.Equal(t, []Type{TypeHotel, TypeRestaurant, TypeAirline, TypeAttraction}, Types())
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    Type
+		expectError bool
+	}{
+		{
+			name:     "upper case type",
+			value:    "AIRLINE",
+			expected: TypeAirline,
+		},
+		{
+			name:     "lower case type with spaces",
+			value:    " attraction ",
+			expected: TypeAttraction,
+		},
+		{
+			name:        "unknown type",
+			value:       "CRUISE",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locationType, err := ParseType(tt.value)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrUnknownType)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, locationType)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package tripadvisor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor/locations"
+)
+
+// ErrUnknownLocationType is returned when a location type is not registered
+var ErrUnknownLocationType = locations.ErrUnknownType
+
+// LocationType is the kind of a TripAdvisor location, such as a hotel or an airline
+type LocationType = locations.Type
+
+// The location types registered by default
+const (
+	LocationTypeHotel      = locations.TypeHotel
+	LocationTypeRestaurant = locations.TypeRestaurant
+	LocationTypeAirline    = locations.TypeAirline
+	LocationTypeAttraction = locations.TypeAttraction
+)
+
+// ReviewsQuery holds the parameters of a request for a page of reviews.
+// QueryID and PageName default to the ones of the location type.
+type ReviewsQuery struct {
+	QueryID    string
+	PageName   string
+	Languages  []string
+	LocationID uint32
+	GeoID      uint32
+	Offset     uint32
+	Limit      uint32
+	SortBy     string
+
+	// DisableMachineTranslation requests the reviews as written instead of translated into the first language
+	DisableMachineTranslation bool
+
+	// Filters narrows down the reviews on top of their language
+	Filters ReviewFilters
+
+	// PhotosPerReview is the maximum number of photos returned with each review
+	PhotosPerReview uint32
+}
+
+// LocationTypeDescriptor describes how the locations of a type are found in URLs and how their reviews are requested
+type LocationTypeDescriptor struct {
+	// Descriptor describes how the locations of the type are found in URLs
+	locations.Descriptor
+
+	// QueryID is the pre-registered query ID of the reviews
+	QueryID string
+
+	// BuildRequest builds the body of a request for a page of reviews
+	BuildRequest func(query ReviewsQuery) BatchRequests
+}
+
+var (
+	locationTypesMu sync.RWMutex
+	locationTypes   = map[LocationType]*LocationTypeDescriptor{}
+
+	// locationTypeOrder keeps the registration order so that LocationTypes is deterministic
+	locationTypeOrder []LocationType
+)
+
+func init() {
+	RegisterLocationType(&LocationTypeDescriptor{
+		Descriptor:   defaultDescriptor(LocationTypeHotel),
+		QueryID:      HotelQueryID,
+		BuildRequest: buildLocationReviewsRequest,
+	})
+	RegisterLocationType(&LocationTypeDescriptor{
+		Descriptor:   defaultDescriptor(LocationTypeRestaurant),
+		QueryID:      RestaurantQueryID,
+		BuildRequest: buildRestaurantReviewsRequest,
+	})
+	RegisterLocationType(&LocationTypeDescriptor{
+		Descriptor:   defaultDescriptor(LocationTypeAirline),
+		QueryID:      AirlineQueryID,
+		BuildRequest: buildAirlineReviewsRequest,
+	})
+	RegisterLocationType(&LocationTypeDescriptor{
+		Descriptor:   defaultDescriptor(LocationTypeAttraction),
+		QueryID:      AttractionQueryID,
+		BuildRequest: buildLocationReviewsRequest,
+	})
+}
+
+// defaultDescriptor returns a copy of the URL descriptor the locations package registers by default for the given type
+func defaultDescriptor(locationType LocationType) locations.Descriptor {
+	descriptor, err := locations.Lookup(locationType)
+	if err != nil {
+		panic(err)
+	}
+	return *descriptor
+}
+
+// RegisterLocationType registers a location type, replacing any descriptor previously registered for the same type.
+// Its URL descriptor is registered in the locations package too, so that its URLs are parsed.
+// The URL regexp is derived from the page name when it is not set.
+func RegisterLocationType(descriptor *LocationTypeDescriptor) {
+	locations.Register(&descriptor.Descriptor)
+
+	locationTypesMu.Lock()
+	defer locationTypesMu.Unlock()
+
+	if _, ok := locationTypes[descriptor.Type]; !ok {
+		locationTypeOrder = append(locationTypeOrder, descriptor.Type)
+	}
+	locationTypes[descriptor.Type] = descriptor
+}
+
+// LookupLocationType returns the descriptor of the given location type
+func LookupLocationType(locationType LocationType) (*LocationTypeDescriptor, error) {
+	locationTypesMu.RLock()
+	defer locationTypesMu.RUnlock()
+
+	descriptor, ok := locationTypes[locationType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLocationType, locationType)
+	}
+	return descriptor, nil
+}
+
+// LocationTypes returns the registered location types in registration order
+func LocationTypes() []LocationType {
+	locationTypesMu.RLock()
+	defer locationTypesMu.RUnlock()
+
+	return append([]LocationType(nil), locationTypeOrder...)
+}
+
+// ParseLocationType parses a location type registered with RegisterLocationType, ignoring case
+func ParseLocationType(value string) (LocationType, error) {
+	locationType := LocationType(strings.ToUpper(strings.TrimSpace(value)))
+	if _, err := LookupLocationType(locationType); err != nil {
+		return "", err
+	}
+	return locationType, nil
+}
@@ -0,0 +1,229 @@
+package tripadvisor
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor/locations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocationType(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    LocationType
+		expectError bool
+	}{
+		{
+			name:     "upper case type",
+			value:    "HOTEL",
+			expected: LocationTypeHotel,
+		},
+		{
+			name:     "lower case type with spaces",
+			value:    " resto ",
+			expected: LocationTypeRestaurant,
+		},
+		{
+			name:        "unknown type",
+			value:       "CRUISE",
+			expectError: true,
+		},
+		{
+			name:        "empty type",
+			value:       "",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locationType, err := ParseLocationType(tt.value)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrUnknownLocationType)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, locationType)
+		})
+	}
+}
+
+func TestLocationTypeURLRegexp(t *testing.T) {
+	tests := []struct {
+		name         string
+		locationType LocationType
+		url          string
+		expected     bool
+	}{
+		{
+			name:         "hotel URL",
+			locationType: LocationTypeHotel,
+			url:          "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+			expected:     true,
+		},
+		{
+			name:         "restaurant URL on a regional domain",
+			locationType: LocationTypeRestaurant,
+			url:          "https://www.tripadvisor.co.uk/Restaurant_Review-g187147-d1751525-Reviews-Cafe_Le_Dome-Paris_Ile_de_France.html",
+			expected:     true,
+		},
+		{
+			name:         "airline URL",
+			locationType: LocationTypeAirline,
+			url:          "https://www.tripadvisor.com/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected:     true,
+		},
+		{
+			name:         "attraction URL",
+			locationType: LocationTypeAttraction,
+			url:          "https://www.tripadvisor.com/Attraction_Review-g187147-d188151-Reviews-Eiffel_Tower-Paris_Ile_de_France.html",
+			expected:     true,
+		},
+		{
+			name:         "URL of another type",
+			locationType: LocationTypeAirline,
+			url:          "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+			expected:     false,
+		},
+		{
+			name:         "airline URL with a geo ID",
+			locationType: LocationTypeAirline,
+			url:          "https://www.tripadvisor.com/Airline_Review-g1-d8729113-Reviews-Lufthansa",
+			expected:     false,
+		},
+		{
+			name:         "non TripAdvisor domain",
+			locationType: LocationTypeHotel,
+			url:          "https://www.example.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace.html",
+			expected:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			descriptor, err := LookupLocationType(tt.locationType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, descriptor.URLRegexp.MatchString(tt.url))
+		})
+	}
+}
+
+// batchRecorder returns a server answering with an empty page and recording the query ID and route page of every batch it receives
+func batchRecorder(t *testing.T, queryIDs *[]string, pageNames *[]string) *httptest.Server {
+	return newGraphQLServer(t, func(batch []graphQLRequest) reviewListPage {
+		for _, request := range batch {
+			*queryIDs = append(*queryIDs, request.Extensions.PreRegisteredQueryID)
+			if len(request.Variables.RoutesRequest) > 0 {
+				*pageNames = append(*pageNames, request.Variables.RoutesRequest[0].Page)
+			}
+		}
+		return reviewListPage{}
+	})
+}
+
+func TestClientReviewsRequestOfLocationType(t *testing.T) {
+	tests := []struct {
+		name              string
+		locationType      LocationType
+		expectedQueryIDs  []string
+		expectedPageNames []string
+	}{
+		{
+			name:              "hotel",
+			locationType:      LocationTypeHotel,
+			expectedQueryIDs:  []string{HotelQueryID, HotelQueryID},
+			expectedPageNames: []string{"Hotel_Review"},
+		},
+		{
+			name:              "restaurant also requests the Michelin status",
+			locationType:      LocationTypeRestaurant,
+			expectedQueryIDs:  []string{RestaurantQueryID, RestaurantQueryID, MichelinQueryID},
+			expectedPageNames: []string{"Restaurant_Review"},
+		},
+		{
+			name:              "attraction",
+			locationType:      LocationTypeAttraction,
+			expectedQueryIDs:  []string{AttractionQueryID, AttractionQueryID},
+			expectedPageNames: []string{"Attraction_Review"},
+		},
+		{
+			name:             "airline",
+			locationType:     LocationTypeAirline,
+			expectedQueryIDs: []string{AirlineQueryID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queryIDs, pageNames []string
+			server := batchRecorder(t, &queryIDs, &pageNames)
+			defer server.Close()
+
+			client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+			_, err := client.Reviews(context.Background(), &Location{Type: tt.locationType, GeoID: 1, LocationID: 1}, ReviewsOptions{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedQueryIDs, queryIDs)
+			assert.Equal(t, tt.expectedPageNames, pageNames)
+		})
+	}
+}
+
+func TestClientReviewsUnknownLocationType(t *testing.T) {
+	var queryIDs, pageNames []string
+	server := batchRecorder(t, &queryIDs, &pageNames)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+	_, err := client.Reviews(context.Background(), &Location{Type: "CRUISE", LocationID: 1}, ReviewsOptions{})
+
+	assert.ErrorIs(t, err, ErrUnknownLocationType)
+	assert.Empty(t, queryIDs, "no request is sent for an unknown type")
+}
+
+func TestRegisterLocationType(t *testing.T) {
+	const vacationRental LocationType = "VACATION_RENTAL"
+	RegisterLocationType(&LocationTypeDescriptor{
+		Descriptor: locations.Descriptor{
+			Type:      vacationRental,
+			Label:     "Vacation rental",
+			PageName:  "VacationRentalReview",
+			GeoScoped: true,
+		},
+		QueryID:      "0123456789abcdef",
+		BuildRequest: buildLocationReviewsRequest,
+	})
+	// The URL descriptor stays registered in the locations package, whose registry no test of this package lists
+	t.Cleanup(func() {
+		locationTypesMu.Lock()
+		defer locationTypesMu.Unlock()
+		delete(locationTypes, vacationRental)
+		locationTypeOrder = slices.DeleteFunc(locationTypeOrder, func(locationType LocationType) bool {
+			return locationType == vacationRental
+		})
+	})
+
+	assert.Equal(t, []LocationType{LocationTypeHotel, LocationTypeRestaurant, LocationTypeAirline, LocationTypeAttraction, vacationRental}, LocationTypes())
+
+	// The URLs of the new type are parsed like the ones of the built-in types
+	location, err := ParseLocation("https://www.tripadvisor.com/VacationRentalReview-g187147-d1234567-Reviews-Loft_Marais-Paris_Ile_de_France.html", "")
+	assert.NoError(t, err)
+	assert.Equal(t, vacationRental, location.Type)
+	assert.Equal(t, "Loft_Marais", location.Name)
+	assert.Equal(t, "https://www.tripadvisor.com/VacationRentalReview-g187147-d1234567-Reviews-Loft_Marais-Paris_Ile_de_France.html", location.URL)
+
+	// And their reviews are requested with the query ID and page name of the descriptor
+	var queryIDs, pageNames []string
+	server := batchRecorder(t, &queryIDs, &pageNames)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+	_, err = client.Reviews(context.Background(), location, ReviewsOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0123456789abcdef", "0123456789abcdef"}, queryIDs)
+	assert.Equal(t, []string{"VacationRentalReview"}, pageNames)
+}
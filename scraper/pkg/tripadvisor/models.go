@@ -1,7 +1,5 @@
 package tripadvisor
 
-import "regexp"
-
 const (
 
 	// EndPointURL is the URL to the TripAdvisor GraphQL endpoint
@@ -16,18 +14,26 @@ const (
 	// AttractionQueryID is the pre-registered query ID for attraction reviews
 	AttractionQueryID string = "ef1a9f94012220d3"
 
-	// RestaurantQueryID is the pre-registered query ID for getting Michelin Star status of restaurants
+	// RestaurantQueryID is the pre-registered query ID for restaurant reviews
+	RestaurantQueryID string = "ef1a9f94012220d3"
+
+	// MichelinQueryID is the pre-registered query ID for getting Michelin Star status of restaurants
 	MichelinQueryID string = "496720f897546a4e"
 
 	// ReviewLimit is the maximum number of reviews that can be fetched in a single request
 	ReviewLimit uint32 = 20
-)
 
-var (
-	tripAdvisorHotelURLRegexp   = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Hotel_Review-g\d{6,10}-d\d{1,10}-Reviews-[\w-]{1,255}\.html$`)
-	tripAdvisorRestaurantRegexp = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Restaurant_Review-g\d{6,10}-d\d{1,10}-Reviews-[\w-]{1,255}\.html$`)
-	tripAdvisorAirlineRegexp    = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Airline_Review-d\d{6,10}-Reviews-[\w-]{1,255}$`)
-	tripAdvisorAttractionRegexp = regexp.MustCompile(`^https:\/\/www\.tripadvisor\.com\/Attraction_Review-g\d{6,10}-d\d{1,10}-Reviews-[\w-]{1,255}\.html$`)
+	// DefaultPhotosPerReview is the number of photos returned with each review unless requested otherwise
+	DefaultPhotosPerReview uint32 = 7
+
+	// SortByServerDetermined lets TripAdvisor decide the order of the reviews
+	SortByServerDetermined string = "SERVER_DETERMINED"
+
+	// SortByDate returns the newest reviews first
+	SortByDate string = "DATE"
+
+	// SortByHelpful returns the reviews with the most helpful votes first
+	SortByHelpful string = "HELPFUL_VOTES"
 )
 
 // Filter is a struct that represents the filter object in the request body to TripAdvisor endpoints
@@ -143,28 +149,110 @@ type ReviewUserProfile struct {
 	} `json:"contributionCounts"`
 }
 
+// ManagementResponse is the reply of the management of the location to a review
+type ManagementResponse struct {
+	ID            int    `json:"id"`
+	Text          string `json:"text"`
+	Language      string `json:"language"`
+	PublishedDate string `json:"publishedDate"`
+	Username      string `json:"username"`
+
+	// ConnectionToSubject is the title of the responder, such as General Manager
+	ConnectionToSubject string `json:"connectionToSubject"`
+
+	UserProfile struct {
+		DisplayName string `json:"displayName"`
+	} `json:"userProfile"`
+}
+
+// ReviewPhoto is a photo attached to a review. Its URL template holds {width} and {height} placeholders
+// to be replaced by the requested size, up to the maximum size of the photo.
+type ReviewPhoto struct {
+	ID               int    `json:"id"`
+	Caption          string `json:"caption"`
+	PhotoSizeDynamic struct {
+		URLTemplate string `json:"urlTemplate"`
+		MaxHeight   int    `json:"maxHeight"`
+		MaxWidth    int    `json:"maxWidth"`
+	} `json:"photoSizeDynamic"`
+}
+
 // Review is a struct that represents the review object in the response body from TripAdvisor endpoints
 type Review struct {
-	ID              int      `json:"id"`
-	Status          string   `json:"status"`
-	CreatedDate     string   `json:"createdDate"`
-	PublishedDate   string   `json:"publishedDate"`
-	Rating          int      `json:"rating"`
-	PublishPlatform string   `json:"publishPlatform"`
-	Title           string   `json:"title"`
-	Language        string   `json:"language"`
-	Text            string   `json:"text"`
-	Username        string   `json:"username"`
-	LocationID      int      `json:"locationId"`
-	HelpfulVotes    int      `json:"helpfulVotes"`
-	Labels          []string `json:"labels"`
-	PhotoIds        []int    `json:"photoIds"`
+	ID              int           `json:"id"`
+	Status          string        `json:"status"`
+	CreatedDate     string        `json:"createdDate"`
+	PublishedDate   string        `json:"publishedDate"`
+	Rating          int           `json:"rating"`
+	PublishPlatform string        `json:"publishPlatform"`
+	Title           string        `json:"title"`
+	Language        string        `json:"language"`
+	Text            string        `json:"text"`
+	Username        string        `json:"username"`
+	LocationID      int           `json:"locationId"`
+	HelpfulVotes    int           `json:"helpfulVotes"`
+	Labels          []string      `json:"labels"`
+	PhotoIds        []int         `json:"photoIds"`
+	Photos          []ReviewPhoto `json:"photos"`
 	TripInfo        struct {
 		StayDate string `json:"stayDate"`
 		TripType string `json:"tripType"`
 	} `json:"tripInfo"`
-	Location    ReviewLocation    `json:"location"`
-	UserProfile ReviewUserProfile `json:"userProfile"`
+	Location     ReviewLocation      `json:"location"`
+	UserProfile  ReviewUserProfile   `json:"userProfile"`
+	MgmtResponse *ManagementResponse `json:"mgmtResponse"`
+
+	// Flight is the flight an airline review is about. It is nil for the reviews of other location types.
+	Flight *FlightInfo `json:"flight,omitempty"`
+
+	// SubRatings are the ratings of the aspects of the location, such as cleanliness, keyed by aspect.
+	// They are decoded from the additional ratings of the TripAdvisor response.
+	SubRatings map[string]int `json:"subRatings,omitempty"`
+
+	// OriginalLanguage is the language the review was written in, as detected by TripAdvisor
+	OriginalLanguage string `json:"originalLanguage,omitempty"`
+
+	// TranslationType is how the title and text were translated, such as MACHINE. It is empty for a review shown as written.
+	TranslationType string `json:"translationType,omitempty"`
+
+	// OriginalTitle and OriginalText are the title and text as written by the reviewer, which Title and Text may be a translation of.
+	// They are only set when ReviewsOptions.OriginalText is, and are not part of the TripAdvisor response.
+	OriginalTitle string `json:"originalTitle,omitempty"`
+	OriginalText  string `json:"originalText,omitempty"`
+
+	// RequestedLanguage is the language the review was requested under when each language is scraped separately.
+	// It is not part of the TripAdvisor response.
+	RequestedLanguage string `json:"requestedLanguage,omitempty"`
+}
+
+// Airport is an airport served by a flight
+type Airport struct {
+	// Code is the IATA code of the airport, such as GVA
+	Code string `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+// FlightInfo describes the flight an airline review is about
+type FlightInfo struct {
+	Origin      Airport `json:"origin"`
+	Destination Airport `json:"destination"`
+
+	// CabinClass is the class the reviewer flew in, such as ECONOMY, PREMIUM_ECONOMY, BUSINESS or FIRST
+	CabinClass string `json:"cabinClass"`
+
+	// FlightType is FlightTypeInternational or FlightTypeDomestic
+	FlightType string `json:"flightType"`
+
+	// Aircraft is the aircraft type, such as Airbus A320
+	Aircraft string `json:"aircraft,omitempty"`
+}
+
+// ReviewKeyword is a keyword frequently mentioned in the reviews of a location, as returned with airline reviews
+type ReviewKeyword struct {
+	Keyword string `json:"keyword"`
+
+	// Count is the number of reviews mentioning the keyword
+	Count int `json:"count,omitempty"`
 }
 
 // MichelinAward represents a single Michelin award for a restaurant.
@@ -190,11 +278,13 @@ type MichelinInfo struct {
 	Summaries     []MichelinSummary `json:"summaries,omitempty"`
 }
 
-// ScrapeResult holds the complete output of a scrape operation,
-// including reviews and optional Michelin data for restaurants.
+// ScrapeResult holds the complete output of a scrape operation, including reviews,
+// optional Michelin data for restaurants and the keywords of the reviews of airlines.
 type ScrapeResult struct {
-	Reviews  []Review      `json:"reviews"`
-	Michelin *MichelinInfo `json:"michelin,omitempty"`
+	Reviews  []Review        `json:"reviews"`
+	Michelin *MichelinInfo   `json:"michelin,omitempty"`
+	Keywords []ReviewKeyword `json:"keywords,omitempty"`
+	Partial  bool            `json:"partial,omitempty"`
 }
 
 // Response is a struct that represents the response body from TripAdvisor endpoints
@@ -219,6 +309,9 @@ type Response struct {
 
 		Michelin []MichelinInfo `json:"RestaurantAwards_getRestaurantAwards"`
 	} `json:"data"`
+
+	// Keywords are the review keywords returned along with airline reviews, decoded by UnmarshalJSON
+	Keywords []ReviewKeyword `json:"-"`
 }
 
 // Responses is a slice of Response structs
@@ -0,0 +1,249 @@
+package tripadvisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ParquetReview is a row of a Parquet output. Unlike the CSV columns, values keep their types,
+// the user profile is flattened into user_ columns, the management response into response_ and responder_ columns,
+// the flight of an airline review into the airport, cabin_class, flight_type and aircraft columns,
+// the sub-ratings into sub_rating_ columns that are empty for the aspects a review does not rate,
+// and the labels, photo IDs and photo URLs are list columns.
+type ParquetReview struct {
+	ID                int64      `parquet:"id"`
+	LocationID        int64      `parquet:"location_id"`
+	LocationName      string     `parquet:"location_name,dict"`
+	Title             string     `parquet:"title"`
+	Text              string     `parquet:"text"`
+	Rating            int32      `parquet:"rating"`
+	Language          string     `parquet:"language,dict"`
+	RequestedLanguage string     `parquet:"requested_language,dict"`
+	OriginalTitle     string     `parquet:"original_title"`
+	OriginalText      string     `parquet:"original_text"`
+	OriginalLanguage  string     `parquet:"original_language,dict"`
+	TranslationType   string     `parquet:"translation_type,dict"`
+	Status            string     `parquet:"status,dict"`
+	PublishPlatform   string     `parquet:"publish_platform,dict"`
+	CreatedDate       *time.Time `parquet:"created_date,timestamp(millisecond),optional"`
+	PublishedDate     *time.Time `parquet:"published_date,timestamp(millisecond),optional"`
+	StayDate          *time.Time `parquet:"stay_date,timestamp(millisecond),optional"`
+	TripType          string     `parquet:"trip_type,dict"`
+	HelpfulVotes      int32      `parquet:"helpful_votes"`
+	Labels            []string   `parquet:"labels,list"`
+	PhotoIDs          []int64    `parquet:"photo_ids,list"`
+	PhotoURLs         []string   `parquet:"photo_urls,list"`
+	UserID            string     `parquet:"user_id"`
+	UserName          string     `parquet:"user_name"`
+	UserDisplayName   string     `parquet:"user_display_name"`
+	UserVerified      bool       `parquet:"user_verified"`
+	UserHometown      string     `parquet:"user_hometown"`
+	UserContributions int32      `parquet:"user_contributions"`
+	UserProfileURL    string     `parquet:"user_profile_url"`
+	UserAvatarURL     string     `parquet:"user_avatar_url"`
+	ResponseText      string     `parquet:"response_text"`
+	ResponseLanguage  string     `parquet:"response_language,dict"`
+	ResponseDate      *time.Time `parquet:"response_date,timestamp(millisecond),optional"`
+	Responder         string     `parquet:"responder"`
+	ResponderTitle    string     `parquet:"responder_title,dict"`
+
+	OriginAirport      string `parquet:"origin_airport,dict"`
+	DestinationAirport string `parquet:"destination_airport,dict"`
+	CabinClass         string `parquet:"cabin_class,dict"`
+	FlightType         string `parquet:"flight_type,dict"`
+	Aircraft           string `parquet:"aircraft,dict"`
+
+	SubRatingValue        *int32 `parquet:"sub_rating_value,optional"`
+	SubRatingRooms        *int32 `parquet:"sub_rating_rooms,optional"`
+	SubRatingLocation     *int32 `parquet:"sub_rating_location,optional"`
+	SubRatingCleanliness  *int32 `parquet:"sub_rating_cleanliness,optional"`
+	SubRatingService      *int32 `parquet:"sub_rating_service,optional"`
+	SubRatingSleepQuality *int32 `parquet:"sub_rating_sleep_quality,optional"`
+	SubRatingFood         *int32 `parquet:"sub_rating_food,optional"`
+	SubRatingAtmosphere   *int32 `parquet:"sub_rating_atmosphere,optional"`
+
+	SubRatingLegroom               *int32 `parquet:"sub_rating_legroom,optional"`
+	SubRatingSeatComfort           *int32 `parquet:"sub_rating_seat_comfort,optional"`
+	SubRatingInFlightEntertainment *int32 `parquet:"sub_rating_in_flight_entertainment,optional"`
+	SubRatingCustomerService       *int32 `parquet:"sub_rating_customer_service,optional"`
+	SubRatingValueForMoney         *int32 `parquet:"sub_rating_value_for_money,optional"`
+	SubRatingCheckInAndBoarding    *int32 `parquet:"sub_rating_check_in_and_boarding,optional"`
+	SubRatingFoodAndBeverage       *int32 `parquet:"sub_rating_food_and_beverage,optional"`
+}
+
+// ReviewToParquetRow converts a single Review into a Parquet row.
+// Dates that can not be parsed are left empty.
+func ReviewToParquetRow(r Review, locationName string) ParquetReview {
+	row := ParquetReview{
+		ID:                int64(r.ID),
+		LocationID:        int64(r.LocationID),
+		LocationName:      locationName,
+		Title:             r.Title,
+		Text:              r.Text,
+		Rating:            int32(r.Rating),
+		Language:          r.Language,
+		RequestedLanguage: r.RequestedLanguage,
+		OriginalTitle:     r.OriginalTitle,
+		OriginalText:      r.OriginalText,
+		OriginalLanguage:  r.OriginalLanguage,
+		TranslationType:   r.TranslationType,
+		Status:            r.Status,
+		PublishPlatform:   r.PublishPlatform,
+		CreatedDate:       parseReviewDate(r.CreatedDate),
+		PublishedDate:     parseReviewDate(r.PublishedDate),
+		StayDate:          parseReviewDate(r.TripInfo.StayDate),
+		TripType:          r.TripInfo.TripType,
+		HelpfulVotes:      int32(r.HelpfulVotes),
+		Labels:            r.Labels,
+		PhotoURLs:         r.PhotoURLs(),
+		UserID:            r.UserProfile.ID,
+		UserName:          r.UserProfile.Username,
+		UserDisplayName:   r.UserProfile.DisplayName,
+		UserVerified:      r.UserProfile.IsVerified,
+		UserContributions: int32(r.UserProfile.ContributionCounts.SumAllUgc),
+		UserProfileURL:    r.UserProfile.Route.URL,
+		UserAvatarURL:     r.UserProfile.Avatar.Data.PhotoSizeDynamic.URLTemplate,
+
+		SubRatingValue:        parquetSubRating(r, SubRatingValue),
+		SubRatingRooms:        parquetSubRating(r, SubRatingRooms),
+		SubRatingLocation:     parquetSubRating(r, SubRatingLocation),
+		SubRatingCleanliness:  parquetSubRating(r, SubRatingCleanliness),
+		SubRatingService:      parquetSubRating(r, SubRatingService),
+		SubRatingSleepQuality: parquetSubRating(r, SubRatingSleepQuality),
+		SubRatingFood:         parquetSubRating(r, SubRatingFood),
+		SubRatingAtmosphere:   parquetSubRating(r, SubRatingAtmosphere),
+
+		SubRatingLegroom:               parquetSubRating(r, SubRatingLegroom),
+		SubRatingSeatComfort:           parquetSubRating(r, SubRatingSeatComfort),
+		SubRatingInFlightEntertainment: parquetSubRating(r, SubRatingInFlightEntertainment),
+		SubRatingCustomerService:       parquetSubRating(r, SubRatingCustomerService),
+		SubRatingValueForMoney:         parquetSubRating(r, SubRatingValueForMoney),
+		SubRatingCheckInAndBoarding:    parquetSubRating(r, SubRatingCheckInAndBoarding),
+		SubRatingFoodAndBeverage:       parquetSubRating(r, SubRatingFoodAndBeverage),
+	}
+
+	if hometown, ok := r.UserProfile.Hometown.FallbackString.(string); ok {
+		row.UserHometown = hometown
+	}
+
+	for _, id := range r.PhotoIds {
+		row.PhotoIDs = append(row.PhotoIDs, int64(id))
+	}
+
+	if response := r.MgmtResponse; response != nil {
+		row.ResponseText = response.Text
+		row.ResponseLanguage = response.Language
+		row.ResponseDate = parseReviewDate(response.PublishedDate)
+		row.Responder = response.Responder()
+		row.ResponderTitle = response.ConnectionToSubject
+	}
+
+	if flight := r.Flight; flight != nil {
+		row.OriginAirport = flight.Origin.Code
+		row.DestinationAirport = flight.Destination.Code
+		row.CabinClass = flight.CabinClass
+		row.FlightType = flight.FlightType
+		row.Aircraft = flight.Aircraft
+	}
+
+	return row
+}
+
+// parquetSubRating returns the rating of the review for the aspect, or nil if the review does not rate it
+func parquetSubRating(r Review, aspect string) *int32 {
+	rating := r.SubRating(aspect)
+	if rating == nil {
+		return nil
+	}
+	value := int32(*rating)
+	return &value
+}
+
+// parseReviewDate parses a date such as 2025-06-15, or a timestamp such as 2025-06-15T10:00:00Z
+func parseReviewDate(value string) *time.Time {
+	for _, layout := range []string{createdDateLayout, time.RFC3339} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return &date
+		}
+	}
+	return nil
+}
+
+// ParquetReviewWriter writes ParquetReview rows, one row group per page of reviews.
+// The location name, the Michelin data, the keywords, the partial flag and the management response figures are written to the key/value metadata of the file when the writer is closed.
+// A Parquet file can only be read once its footer is written, so an output left behind by a killed run can not be resumed.
+type ParquetReviewWriter struct {
+	writer    *parquet.GenericWriter[ParquetReview]
+	meta      *ScrapeMetadata
+	responses ResponseStats
+}
+
+// NewParquetReviewWriter returns a ParquetReviewWriter writing to w
+func NewParquetReviewWriter(w io.Writer) *ParquetReviewWriter {
+	return &ParquetReviewWriter{writer: parquet.NewGenericWriter[ParquetReview](w)}
+}
+
+// Begin keeps the metadata. The schema is written with the footer.
+func (p *ParquetReviewWriter) Begin(meta *ScrapeMetadata) error {
+	p.meta = meta
+	return nil
+}
+
+// Write writes the reviews as a row group
+func (p *ParquetReviewWriter) Write(reviews []Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	rows := make([]ParquetReview, 0, len(reviews))
+	for _, r := range reviews {
+		rows = append(rows, ReviewToParquetRow(r, p.meta.LocationName))
+	}
+	p.responses.Add(reviews)
+
+	if _, err := p.writer.Write(rows); err != nil {
+		return fmt.Errorf("error writing data to parquet: %w", err)
+	}
+	if err := p.writer.Flush(); err != nil {
+		return fmt.Errorf("error flushing parquet row group: %w", err)
+	}
+	return nil
+}
+
+// Close writes the metadata and the footer of the file
+func (p *ParquetReviewWriter) Close() error {
+	if p.meta != nil {
+		p.writer.SetKeyValueMetadata("location_name", p.meta.LocationName)
+		p.writer.SetKeyValueMetadata("partial", strconv.FormatBool(p.meta.Partial))
+		p.writer.SetKeyValueMetadata("response_rate", strconv.FormatFloat(p.responses.ResponseRate(), 'f', -1, 64))
+		if days := p.responses.MedianResponseDays(); days != nil {
+			p.writer.SetKeyValueMetadata("median_response_days", strconv.FormatFloat(*days, 'f', -1, 64))
+		}
+
+		if p.meta.Michelin != nil {
+			data, err := json.Marshal(p.meta.Michelin)
+			if err != nil {
+				return fmt.Errorf("error marshalling Michelin data: %w", err)
+			}
+			p.writer.SetKeyValueMetadata("michelin", string(data))
+		}
+
+		if len(p.meta.Keywords) > 0 {
+			data, err := json.Marshal(p.meta.Keywords)
+			if err != nil {
+				return fmt.Errorf("error marshalling keywords: %w", err)
+			}
+			p.writer.SetKeyValueMetadata("keywords", string(data))
+		}
+	}
+
+	if err := p.writer.Close(); err != nil {
+		return fmt.Errorf("error closing parquet: %w", err)
+	}
+	return nil
+}
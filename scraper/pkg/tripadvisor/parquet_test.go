@@ -0,0 +1,151 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewToParquetRow(t *testing.T) {
+	review := Review{
+		ID:              42,
+		Status:          "PUBLISHED",
+		CreatedDate:     "2025-06-15",
+		PublishedDate:   "not a date",
+		Rating:          5,
+		PublishPlatform: "OTHER",
+		Title:           "Great",
+		Language:        "en",
+		Text:            "Loved it",
+		LocationID:      231860,
+		HelpfulVotes:    3,
+		Labels:          []string{"family"},
+		PhotoIds:        []int{7, 8},
+	}
+	review.TripInfo.StayDate = "2025-06-01"
+	review.TripInfo.TripType = "FAMILY"
+	review.UserProfile.ID = "ABC"
+	review.UserProfile.DisplayName = "Jane D"
+	review.UserProfile.Username = "janed"
+	review.UserProfile.IsVerified = true
+	review.UserProfile.Hometown.FallbackString = "Lausanne, Switzerland"
+	review.UserProfile.Route.URL = "/Profile/janed"
+	review.UserProfile.ContributionCounts.SumAllUgc = 12
+
+	createdDate := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	stayDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	expected := ParquetReview{
+		ID:                42,
+		LocationID:        231860,
+		LocationName:      "Test_Hotel",
+		Title:             "Great",
+		Text:              "Loved it",
+		Rating:            5,
+		Language:          "en",
+		Status:            "PUBLISHED",
+		PublishPlatform:   "OTHER",
+		CreatedDate:       &createdDate,
+		StayDate:          &stayDate,
+		TripType:          "FAMILY",
+		HelpfulVotes:      3,
+		Labels:            []string{"family"},
+		PhotoIDs:          []int64{7, 8},
+		UserID:            "ABC",
+		UserName:          "janed",
+		UserDisplayName:   "Jane D",
+		UserVerified:      true,
+		UserHometown:      "Lausanne, Switzerland",
+		UserContributions: 12,
+		UserProfileURL:    "/Profile/janed",
+	}
+
+	assert.Equal(t, expected, ReviewToParquetRow(review, "Test_Hotel"))
+}
+
+func TestParquetReviewWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewReviewWriter("parquet", &buf, nil)
+	assert.NoError(t, err)
+
+	meta := &ScrapeMetadata{
+		LocationName: "Star_Restaurant",
+		Michelin:     &MichelinInfo{AwardHeader: "MICHELIN Guide"},
+		Keywords:     []ReviewKeyword{{Keyword: "tasting menu", Count: 5}},
+	}
+	assert.NoError(t, writer.Begin(meta))
+	assert.NoError(t, writer.Write([]Review{{ID: 1, Rating: 5, CreatedDate: "2025-06-15", SubRatings: map[string]int{SubRatingFood: 5}}, {ID: 2, Rating: 4, CreatedDate: "2025-06-14", MgmtResponse: &ManagementResponse{Text: "Thanks", PublishedDate: "2025-06-18", Username: "manager"}}}))
+	assert.NoError(t, writer.Write(nil))
+	assert.NoError(t, writer.Write([]Review{{ID: 3, Rating: 1, Labels: []string{"a", "b"}}}))
+	meta.Partial = true
+	assert.NoError(t, writer.Close())
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, file.RowGroups(), 2, "one row group per non-empty page")
+
+	partial, _ := file.Lookup("partial")
+	assert.Equal(t, "true", partial)
+	locationName, _ := file.Lookup("location_name")
+	assert.Equal(t, "Star_Restaurant", locationName)
+	michelin, _ := file.Lookup("michelin")
+	assert.JSONEq(t, `{"awardHeader":"MICHELIN Guide"}`, michelin)
+	keywords, _ := file.Lookup("keywords")
+	assert.JSONEq(t, `[{"keyword":"tasting menu","count":5}]`, keywords)
+	responseRate, _ := file.Lookup("response_rate")
+	assert.Equal(t, "0.3333333333333333", responseRate)
+	medianResponseDays, _ := file.Lookup("median_response_days")
+	assert.Equal(t, "4", medianResponseDays)
+
+	rows, err := parquet.Read[ParquetReview](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []int64{1, 2, 3}, []int64{rows[0].ID, rows[1].ID, rows[2].ID})
+	assert.Equal(t, int32(4), rows[1].Rating)
+	assert.True(t, time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC).Equal(*rows[0].CreatedDate))
+	assert.Nil(t, rows[2].CreatedDate)
+	assert.Equal(t, []string{"a", "b"}, rows[2].Labels)
+	assert.Equal(t, "manager", rows[1].Responder)
+	assert.True(t, time.Date(2025, 6, 18, 0, 0, 0, 0, time.UTC).Equal(*rows[1].ResponseDate))
+	assert.Nil(t, rows[0].ResponseDate)
+	assert.Equal(t, new(int32(5)), rows[0].SubRatingFood)
+	assert.Nil(t, rows[0].SubRatingService)
+	assert.Nil(t, rows[1].SubRatingFood)
+}
+
+func TestParquetReviewWriterCanNotResume(t *testing.T) {
+	checkpoint := NewCheckpoint(CheckpointSettings{LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html", Languages: []string{"en"}, FileType: "parquet"})
+	checkpoint.Record(0, 20, 1024, nil)
+
+	writer, err := NewReviewWriter("parquet", &bytes.Buffer{}, checkpoint)
+	assert.Error(t, err)
+	assert.Nil(t, writer)
+	assert.False(t, CanResume("parquet"))
+	assert.True(t, CanResume("json"))
+}
+
+func TestCutoffFromParquetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.parquet")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+
+	writer := NewParquetReviewWriter(file)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel"}))
+	assert.NoError(t, writer.Write([]Review{
+		{CreatedDate: "2025-05-01", Title: "A", Text: "a"},
+		{CreatedDate: "2025-04-01", Title: "B", Text: "b"},
+	}))
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, file.Close())
+
+	cutoff, err := CutoffFromFile(path)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC).Equal(cutoff.Date))
+	assert.False(t, cutoff.IsNew(Review{CreatedDate: "2025-05-01", Title: "A", Text: "a"}))
+	assert.True(t, cutoff.IsNew(Review{CreatedDate: "2025-05-01", Title: "C", Text: "c"}))
+	assert.True(t, cutoff.IsNew(Review{CreatedDate: "2025-05-02"}))
+}
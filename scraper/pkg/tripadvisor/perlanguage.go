@@ -0,0 +1,104 @@
+package tripadvisor
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// ReviewDeduplicator drops the reviews that were already seen, identified by their ID.
+// A review written in one language may be returned again when another language is requested.
+type ReviewDeduplicator struct {
+	seen map[int]struct{}
+}
+
+// NewReviewDeduplicator returns a ReviewDeduplicator that has already seen the reviews with the given IDs
+func NewReviewDeduplicator(seenIDs ...int) *ReviewDeduplicator {
+	d := &ReviewDeduplicator{seen: make(map[int]struct{}, len(seenIDs))}
+	for _, id := range seenIDs {
+		d.seen[id] = struct{}{}
+	}
+	return d
+}
+
+// FilterNew returns the reviews that were not seen before, in order, and marks them as seen
+func (d *ReviewDeduplicator) FilterNew(reviews []Review) []Review {
+	fresh := make([]Review, 0, len(reviews))
+	for _, r := range reviews {
+		if _, ok := d.seen[r.ID]; ok {
+			continue
+		}
+		d.seen[r.ID] = struct{}{}
+		fresh = append(fresh, r)
+	}
+	return fresh
+}
+
+// TagRequestedLanguage sets the language the reviews were requested under
+func TagRequestedLanguage(reviews []Review, language string) {
+	for i := range reviews {
+		reviews[i].RequestedLanguage = language
+	}
+}
+
+// ReviewCounts fetches the number of reviews of the location in each of the given languages, sending one request per language.
+// Unlike ReviewCount, a language without reviews is counted as 0 instead of failing.
+func (c *Client) ReviewCounts(ctx context.Context, loc *Location, languages ...string) (map[string]int, error) {
+	return c.FilteredReviewCounts(ctx, loc, ReviewsOptions{Languages: languages})
+}
+
+// FilteredReviewCounts is like ReviewCounts for the languages of opts, English by default, counting only the reviews
+// selected by its request options
+func (c *Client) FilteredReviewCounts(ctx context.Context, loc *Location, opts ReviewsOptions) (map[string]int, error) {
+	languages := opts.Languages
+	if len(languages) == 0 {
+		languages = []string{"en"}
+	}
+
+	counts := make(map[string]int, len(languages))
+	for _, language := range languages {
+		opts.Languages = []string{language}
+		count, err := c.CountReviews(ctx, loc, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error counting reviews for language %s: %w", language, err)
+		}
+		counts[language] = count
+	}
+	return counts, nil
+}
+
+// ReviewsPerLanguageSeq is like ReviewsSeq, but requests the reviews of each language of opts separately, English by default.
+// Reviews are tagged with the language they were requested under, and a review returned for several languages is only yielded for the first one.
+// The offset of opts applies to the first language only.
+func (c *Client) ReviewsPerLanguageSeq(ctx context.Context, loc *Location, opts ReviewsOptions) iter.Seq2[Review, error] {
+	return func(yield func(Review, error) bool) {
+		languages := opts.Languages
+		if len(languages) == 0 {
+			languages = []string{"en"}
+		}
+
+		deduplicator := NewReviewDeduplicator()
+		for i, language := range languages {
+			languageOpts := opts
+			languageOpts.Languages = []string{language}
+			if i > 0 {
+				languageOpts.Offset = 0
+			}
+
+			for review, err := range c.ReviewsSeq(ctx, loc, languageOpts) {
+				if err != nil {
+					yield(Review{}, fmt.Errorf("error fetching %s reviews: %w", language, err))
+					return
+				}
+
+				review.RequestedLanguage = language
+				if len(deduplicator.FilterNew([]Review{review})) == 0 {
+					continue
+				}
+				if !yield(review, nil) {
+					return
+				}
+			}
+		}
+	}
+}
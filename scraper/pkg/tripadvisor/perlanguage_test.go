@@ -0,0 +1,64 @@
+package tripadvisor
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewDeduplicator(t *testing.T) {
+	deduplicator := NewReviewDeduplicator(1)
+
+	assert.Equal(t, []Review{{ID: 2}, {ID: 3}}, deduplicator.FilterNew([]Review{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 2}}))
+	assert.Equal(t, []Review{{ID: 4}}, deduplicator.FilterNew([]Review{{ID: 3}, {ID: 4}}))
+	assert.Empty(t, deduplicator.FilterNew([]Review{{ID: 1}, {ID: 4}}))
+}
+
+// languageServer returns a server answering with the reviews of the requested language, given by their IDs
+func languageServer(t *testing.T, reviewsByLanguage map[string][]int) *httptest.Server {
+	return newGraphQLServer(t, func(batch []graphQLRequest) reviewListPage {
+		selections := batch[0].Variables.Filters[0].Selections
+		assert.Len(t, selections, 1, "one language per request")
+
+		ids := reviewsByLanguage[selections[0]]
+		var reviews []Review
+		if batch[0].Variables.Offset == 0 {
+			for _, id := range ids {
+				reviews = append(reviews, Review{ID: id})
+			}
+		}
+		return reviewListPage{TotalCount: len(ids), Reviews: reviews}
+	})
+}
+
+func TestClientReviewCounts(t *testing.T) {
+	server := languageServer(t, map[string][]int{"en": {1, 2, 3}, "fr": {4}})
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+	counts, err := client.ReviewCounts(context.Background(), &Location{Type: LocationTypeHotel, GeoID: 1, LocationID: 1}, "en", "fr", "de")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"en": 3, "fr": 1, "de": 0}, counts)
+}
+
+func TestClientReviewsPerLanguageSeq(t *testing.T) {
+	// Review 2 is returned for both languages
+	server := languageServer(t, map[string][]int{"en": {1, 2}, "fr": {2, 3}})
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+	var reviews []Review
+	for review, err := range client.ReviewsPerLanguageSeq(context.Background(), &Location{Type: LocationTypeHotel, GeoID: 1, LocationID: 1}, ReviewsOptions{Languages: []string{"en", "fr"}}) {
+		assert.NoError(t, err)
+		reviews = append(reviews, review)
+	}
+
+	assert.Equal(t, []Review{
+		{ID: 1, RequestedLanguage: "en"},
+		{ID: 2, RequestedLanguage: "en"},
+		{ID: 3, RequestedLanguage: "fr"},
+	}, reviews)
+}
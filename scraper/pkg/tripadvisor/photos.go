@@ -0,0 +1,220 @@
+package tripadvisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PhotoManifestFile is the name of the manifest written to the directory of the downloaded photos
+const PhotoManifestFile = "photos.ndjson"
+
+// PhotoSize is the size at which photos are requested. A zero width or height requests the maximum one.
+type PhotoSize struct {
+	Width  int
+	Height int
+}
+
+// ParsePhotoSize parses a size such as 800x600, or original for the maximum size
+func ParsePhotoSize(value string) (PhotoSize, error) {
+	if value == "" || strings.EqualFold(value, "original") {
+		return PhotoSize{}, nil
+	}
+
+	width, height, ok := strings.Cut(strings.ToLower(value), "x")
+	if !ok {
+		return PhotoSize{}, fmt.Errorf("invalid photo size %q", value)
+	}
+	w, err := strconv.Atoi(width)
+	if err != nil || w < 1 {
+		return PhotoSize{}, fmt.Errorf("invalid photo width %q", width)
+	}
+	h, err := strconv.Atoi(height)
+	if err != nil || h < 1 {
+		return PhotoSize{}, fmt.Errorf("invalid photo height %q", height)
+	}
+	return PhotoSize{Width: w, Height: h}, nil
+}
+
+// URL returns the URL of the photo at the given size, capped to the maximum size of the photo
+func (p ReviewPhoto) URL(size PhotoSize) string {
+	dynamic := p.PhotoSizeDynamic
+	width := cappedDimension(size.Width, dynamic.MaxWidth)
+	height := cappedDimension(size.Height, dynamic.MaxHeight)
+	return strings.NewReplacer("{width}", strconv.Itoa(width), "{height}", strconv.Itoa(height)).Replace(dynamic.URLTemplate)
+}
+
+// cappedDimension returns the requested dimension, or the maximum one if it is not set or larger
+func cappedDimension(requested int, maximum int) int {
+	if requested == 0 || (maximum > 0 && requested > maximum) {
+		return maximum
+	}
+	return requested
+}
+
+// PhotoURLs returns the URLs of the photos of the review at their maximum size
+func (r Review) PhotoURLs() []string {
+	var urls []string
+	for _, photo := range r.Photos {
+		urls = append(urls, photo.URL(PhotoSize{}))
+	}
+	return urls
+}
+
+// PhotoManifestEntry links a downloaded photo back to its review
+type PhotoManifestEntry struct {
+	ReviewID int    `json:"reviewId"`
+	PhotoID  int    `json:"photoId"`
+	File     string `json:"file"`
+	URL      string `json:"url"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+// PhotoDownloader downloads the photos of reviews into a directory, naming them after the review and photo IDs,
+// and appends an entry per photo to the PhotoManifestFile of the directory.
+// Photos already in the directory, e.g. downloaded before a scrape was resumed, are not downloaded again.
+type PhotoDownloader struct {
+	client   *Client
+	dir      string
+	size     PhotoSize
+	manifest *os.File
+}
+
+// NewPhotoDownloader returns a PhotoDownloader sending its requests with the HTTP client of the given client,
+// creating the directory if needed
+func NewPhotoDownloader(client *Client, dir string, size PhotoSize) (*PhotoDownloader, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating photo directory %s: %w", dir, err)
+	}
+
+	manifest, err := os.OpenFile(filepath.Join(dir, PhotoManifestFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening photo manifest: %w", err)
+	}
+
+	return &PhotoDownloader{client: client, dir: dir, size: size, manifest: manifest}, nil
+}
+
+// Download downloads the photos of the reviews. A photo that can not be downloaded does not stop the others,
+// and the failures are returned together once every photo was tried.
+func (d *PhotoDownloader) Download(ctx context.Context, reviews []Review) error {
+	var errs []error
+	for _, r := range reviews {
+		for _, photo := range r.Photos {
+			if err := d.download(ctx, r.ID, photo); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				errs = append(errs, fmt.Errorf("error downloading photo %d of review %d: %w", photo.ID, r.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the manifest
+func (d *PhotoDownloader) Close() error {
+	if err := d.manifest.Close(); err != nil {
+		return fmt.Errorf("error closing photo manifest: %w", err)
+	}
+	return nil
+}
+
+// download downloads a single photo and records it in the manifest
+func (d *PhotoDownloader) download(ctx context.Context, reviewID int, photo ReviewPhoto) error {
+	photoURL := photo.URL(d.size)
+	fileName := fmt.Sprintf("%d-%d%s", reviewID, photo.ID, photoExtension(photoURL))
+	filePath := filepath.Join(d.dir, fileName)
+	if _, err := os.Stat(filePath); err == nil {
+		return nil
+	}
+
+	// Download to a temporary file first, so that an interrupted download is not mistaken for a complete one
+	tmpFile, err := os.CreateTemp(d.dir, fileName+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating photo file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err := d.client.fetchPhoto(ctx, photoURL, tmpFile); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("error closing photo file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), filePath); err != nil {
+		return fmt.Errorf("error renaming photo file: %w", err)
+	}
+
+	entry, err := json.Marshal(PhotoManifestEntry{ReviewID: reviewID, PhotoID: photo.ID, File: fileName, URL: photoURL, Caption: photo.Caption})
+	if err != nil {
+		return fmt.Errorf("error marshalling photo manifest entry: %w", err)
+	}
+	if _, err := d.manifest.Write(append(entry, '\n')); err != nil {
+		return fmt.Errorf("error writing photo manifest: %w", err)
+	}
+	return nil
+}
+
+// photoExtension returns the extension of the file in the photo URL, .jpg by default
+func photoExtension(photoURL string) string {
+	parsed, err := url.Parse(photoURL)
+	if err != nil {
+		return ".jpg"
+	}
+	if ext := path.Ext(parsed.Path); ext != "" {
+		return ext
+	}
+	return ".jpg"
+}
+
+// fetchPhoto downloads the photo at the given URL to file, retrying the request according to the retry policy of the client.
+// The file is emptied before every attempt, so that a failed attempt leaves nothing behind.
+func (c *Client) fetchPhoto(ctx context.Context, photoURL string, file *os.File) error {
+	return c.withRetries(ctx, func() error {
+		if err := file.Truncate(0); err != nil {
+			return fmt.Errorf("error emptying photo file: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("error emptying photo file: %w", err)
+		}
+		return c.getPhoto(ctx, photoURL, file)
+	})
+}
+
+// getPhoto sends a single GET request for the photo at the given URL and copies it to w
+func (c *Client) getPhoto(ctx context.Context, photoURL string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, photoURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(resp)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("error reading photo: %w", err)
+	}
+	return nil
+}
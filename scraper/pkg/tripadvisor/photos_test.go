@@ -0,0 +1,157 @@
+package tripadvisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePhotoSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    PhotoSize
+		expectError bool
+	}{
+		{name: "empty is the original size", value: "", expected: PhotoSize{}},
+		{name: "original", value: "Original", expected: PhotoSize{}},
+		{name: "width and height", value: "800x600", expected: PhotoSize{Width: 800, Height: 600}},
+		{name: "upper case separator", value: "800X600", expected: PhotoSize{Width: 800, Height: 600}},
+		{name: "missing height", value: "800", expectError: true},
+		{name: "zero width", value: "0x600", expectError: true},
+		{name: "not a number", value: "bigxsmall", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := ParsePhotoSize(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, size)
+			}
+		})
+	}
+}
+
+// testPhoto returns a photo with the given ID, served from the given base URL with a maximum size of 1200x900
+func testPhoto(id int, baseURL string) ReviewPhoto {
+	photo := ReviewPhoto{ID: id, Caption: fmt.Sprintf("Photo %d", id)}
+	photo.PhotoSizeDynamic.URLTemplate = fmt.Sprintf("%s/media/photo-o/%d.jpg?w={width}&h={height}&s=1", baseURL, id)
+	photo.PhotoSizeDynamic.MaxWidth = 1200
+	photo.PhotoSizeDynamic.MaxHeight = 900
+	return photo
+}
+
+func TestReviewPhotoURL(t *testing.T) {
+	photo := testPhoto(7, "https://media.example.com")
+
+	tests := []struct {
+		name     string
+		size     PhotoSize
+		expected string
+	}{
+		{name: "original size", size: PhotoSize{}, expected: "https://media.example.com/media/photo-o/7.jpg?w=1200&h=900&s=1"},
+		{name: "smaller size", size: PhotoSize{Width: 400, Height: 300}, expected: "https://media.example.com/media/photo-o/7.jpg?w=400&h=300&s=1"},
+		{name: "larger size is capped", size: PhotoSize{Width: 4000, Height: 3000}, expected: "https://media.example.com/media/photo-o/7.jpg?w=1200&h=900&s=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, photo.URL(tt.size))
+		})
+	}
+}
+
+func TestPhotoDownloader(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		if strings.Contains(r.URL.Path, "/3.jpg") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, "image %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "reviews-photos")
+	downloader, err := NewPhotoDownloader(NewClient(WithLogger(nil)), dir, PhotoSize{Width: 400, Height: 300})
+	assert.NoError(t, err)
+
+	reviews := []Review{
+		{ID: 1, Photos: []ReviewPhoto{testPhoto(1, server.URL), testPhoto(2, server.URL)}},
+		{ID: 2, Photos: []ReviewPhoto{testPhoto(3, server.URL)}},
+		{ID: 3},
+	}
+	err = downloader.Download(context.Background(), reviews)
+	assert.ErrorContains(t, err, "error downloading photo 3 of review 2")
+
+	// Photos already downloaded are skipped
+	assert.Error(t, downloader.Download(context.Background(), reviews[:2]))
+	assert.NoError(t, downloader.Close())
+	assert.Equal(t, []string{
+		"/media/photo-o/1.jpg?w=400&h=300&s=1",
+		"/media/photo-o/2.jpg?w=400&h=300&s=1",
+		"/media/photo-o/3.jpg?w=400&h=300&s=1",
+		"/media/photo-o/3.jpg?w=400&h=300&s=1",
+	}, requested)
+
+	content, err := os.ReadFile(filepath.Join(dir, "1-2.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image /media/photo-o/2.jpg", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "2-3.jpg"))
+
+	manifest, err := os.Open(filepath.Join(dir, PhotoManifestFile))
+	assert.NoError(t, err)
+	defer manifest.Close()
+
+	var entries []PhotoManifestEntry
+	scanner := bufio.NewScanner(manifest)
+	for scanner.Scan() {
+		var entry PhotoManifestEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	assert.Equal(t, []PhotoManifestEntry{
+		{ReviewID: 1, PhotoID: 1, File: "1-1.jpg", URL: server.URL + "/media/photo-o/1.jpg?w=400&h=300&s=1", Caption: "Photo 1"},
+		{ReviewID: 1, PhotoID: 2, File: "1-2.jpg", URL: server.URL + "/media/photo-o/2.jpg?w=400&h=300&s=1", Caption: "Photo 2"},
+	}, entries)
+}
+
+func TestPhotoDownloaderRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "image %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "reviews-photos")
+	client := NewClient(WithLogger(nil), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}), WithRateLimiter(NewRateLimiter(1000, 1)))
+	downloader, err := NewPhotoDownloader(client, dir, PhotoSize{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, downloader.Download(context.Background(), []Review{{ID: 1, Photos: []ReviewPhoto{testPhoto(1, server.URL)}}}))
+	assert.NoError(t, downloader.Close())
+	assert.Equal(t, 2, requests)
+
+	content, err := os.ReadFile(filepath.Join(dir, "1-1.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image /media/photo-o/1.jpg", string(content))
+}
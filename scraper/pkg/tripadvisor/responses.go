@@ -0,0 +1,81 @@
+package tripadvisor
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Responder returns the name of the member of the management who wrote the response
+func (m ManagementResponse) Responder() string {
+	return cmp.Or(m.UserProfile.DisplayName, m.Username)
+}
+
+// ResponseTime returns how long after the review was published the management responded.
+// It is false if the review has no response or a date can not be parsed.
+func (r Review) ResponseTime() (time.Duration, bool) {
+	if r.MgmtResponse == nil {
+		return 0, false
+	}
+	reviewDate := parseReviewDate(cmp.Or(r.PublishedDate, r.CreatedDate))
+	responseDate := parseReviewDate(r.MgmtResponse.PublishedDate)
+	if reviewDate == nil || responseDate == nil || responseDate.Before(*reviewDate) {
+		return 0, false
+	}
+	return responseDate.Sub(*reviewDate), true
+}
+
+// ResponseStats accumulates how often and how fast the management responds to reviews.
+// It is stored in checkpoints, so that the figures of a resumed scrape cover the reviews of the earlier runs.
+// The response times grow with every review, so they are kept in the journal of the checkpoint instead.
+type ResponseStats struct {
+	Reviews       int             `json:"reviews"`
+	Responded     int             `json:"responded"`
+	ResponseTimes []time.Duration `json:"-"`
+}
+
+// Add counts the reviews and their responses
+func (s *ResponseStats) Add(reviews []Review) {
+	for _, r := range reviews {
+		s.Reviews++
+		if r.MgmtResponse == nil {
+			continue
+		}
+		s.Responded++
+		if responseTime, ok := r.ResponseTime(); ok {
+			s.ResponseTimes = append(s.ResponseTimes, responseTime)
+		}
+	}
+}
+
+// ResponseRate returns the share of the reviews with a response, from 0 to 1
+func (s *ResponseStats) ResponseRate() float64 {
+	if s.Reviews == 0 {
+		return 0
+	}
+	return float64(s.Responded) / float64(s.Reviews)
+}
+
+// MedianResponseTime returns the median time the management took to respond.
+// It is false if no response time is known.
+func (s *ResponseStats) MedianResponseTime() (time.Duration, bool) {
+	if len(s.ResponseTimes) == 0 {
+		return 0, false
+	}
+	sorted := slices.Sorted(slices.Values(s.ResponseTimes))
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle], true
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2, true
+}
+
+// MedianResponseDays returns the median response time in days, or nil if no response time is known
+func (s *ResponseStats) MedianResponseDays() *float64 {
+	median, ok := s.MedianResponseTime()
+	if !ok {
+		return nil
+	}
+	days := median.Hours() / 24
+	return &days
+}
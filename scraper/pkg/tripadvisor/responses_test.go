@@ -0,0 +1,109 @@
+package tripadvisor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagementResponseResponder(t *testing.T) {
+	response := ManagementResponse{Username: "gm123"}
+	assert.Equal(t, "gm123", response.Responder())
+
+	response.UserProfile.DisplayName = "Jane D"
+	assert.Equal(t, "Jane D", response.Responder(), "the display name is preferred")
+}
+
+func TestReviewResponseTime(t *testing.T) {
+	tests := []struct {
+		name       string
+		review     Review
+		expected   time.Duration
+		expectedOK bool
+	}{
+		{
+			name:   "no response",
+			review: Review{PublishedDate: "2025-06-01"},
+		},
+		{
+			name:       "from the published date",
+			review:     Review{CreatedDate: "2025-05-20", PublishedDate: "2025-06-01", MgmtResponse: &ManagementResponse{PublishedDate: "2025-06-03"}},
+			expected:   48 * time.Hour,
+			expectedOK: true,
+		},
+		{
+			name:       "from the created date when the published date is missing",
+			review:     Review{CreatedDate: "2025-06-01", MgmtResponse: &ManagementResponse{PublishedDate: "2025-06-01T12:00:00Z"}},
+			expected:   12 * time.Hour,
+			expectedOK: true,
+		},
+		{
+			name:   "response date can not be parsed",
+			review: Review{PublishedDate: "2025-06-01", MgmtResponse: &ManagementResponse{PublishedDate: "soon"}},
+		},
+		{
+			name:   "response before the review",
+			review: Review{PublishedDate: "2025-06-01", MgmtResponse: &ManagementResponse{PublishedDate: "2025-05-01"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responseTime, ok := tt.review.ResponseTime()
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expected, responseTime)
+		})
+	}
+}
+
+func TestResponseStats(t *testing.T) {
+	respondedAfter := func(days int) Review {
+		return Review{PublishedDate: "2025-06-01", MgmtResponse: &ManagementResponse{PublishedDate: time.Date(2025, 6, 1+days, 0, 0, 0, 0, time.UTC).Format(createdDateLayout)}}
+	}
+
+	tests := []struct {
+		name           string
+		pages          [][]Review
+		expectedRate   float64
+		expectedMedian *float64
+	}{
+		{
+			name: "no reviews",
+		},
+		{
+			name:         "no responses",
+			pages:        [][]Review{{{ID: 1}, {ID: 2}}},
+			expectedRate: 0,
+		},
+		{
+			name:           "odd number of response times",
+			pages:          [][]Review{{respondedAfter(5), {ID: 2}}, {respondedAfter(1), respondedAfter(3)}},
+			expectedRate:   0.75,
+			expectedMedian: new(3.0),
+		},
+		{
+			name:           "even number of response times",
+			pages:          [][]Review{{respondedAfter(1), respondedAfter(4)}},
+			expectedRate:   1,
+			expectedMedian: new(2.5),
+		},
+		{
+			name:           "response without a date counts towards the rate only",
+			pages:          [][]Review{{respondedAfter(2), {MgmtResponse: &ManagementResponse{}}}},
+			expectedRate:   1,
+			expectedMedian: new(2.0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats ResponseStats
+			for _, page := range tt.pages {
+				stats.Add(page)
+			}
+			assert.Equal(t, tt.expectedRate, stats.ResponseRate())
+			assert.Equal(t, tt.expectedMedian, stats.MedianResponseDays())
+		})
+	}
+}
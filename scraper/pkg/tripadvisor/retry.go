@@ -0,0 +1,156 @@
+package tripadvisor
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrRateLimited is returned when TripAdvisor responds with 429 Too Many Requests
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrBlocked is returned when TripAdvisor refuses to serve the request, usually because the IP has been flagged
+	ErrBlocked = errors.New("request blocked")
+
+	// ErrNotFound is returned when the requested location does not exist
+	ErrNotFound = errors.New("location not found")
+
+	// ErrServerError is returned when TripAdvisor responds with a 5xx status code
+	ErrServerError = errors.New("server error")
+
+	// ErrUnexpectedStatus is returned for any other non-200 status code
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+
+	// ErrSchemaChanged is returned when the response body can not be decoded into the expected structure
+	ErrSchemaChanged = errors.New("unexpected response schema")
+)
+
+// StatusError is returned when TripAdvisor responds with a non-200 status code.
+// It wraps one of the sentinel errors so that callers can use errors.Is to classify it.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%v: status code %d", e.Err, e.StatusCode)
+}
+
+// Unwrap returns the sentinel error wrapped by the StatusError
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// newStatusError classifies the given response into a StatusError
+func newStatusError(resp *http.Response) *StatusError {
+	statusErr := &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		statusErr.Err = ErrRateLimited
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		statusErr.Err = ErrBlocked
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		statusErr.Err = ErrNotFound
+	case resp.StatusCode >= http.StatusInternalServerError:
+		statusErr.Err = ErrServerError
+	default:
+		statusErr.Err = ErrUnexpectedStatus
+	}
+
+	return statusErr
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+// It returns 0 if the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+// RetryPolicy describes how failed requests to TripAdvisor are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values below 1 are treated as 1.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles with every following retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts, including delays requested through Retry-After.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used by MakeRequest
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    2 * time.Minute,
+}
+
+// Delay returns how long to wait before the given retry (starting at 1).
+// A positive retryAfter, as sent by the server, takes precedence over the exponential backoff.
+// A MaxDelay of zero or less leaves the delay uncapped.
+func (p RetryPolicy) Delay(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if p.MaxDelay > 0 {
+			return min(retryAfter, p.MaxDelay)
+		}
+		return retryAfter
+	}
+
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	// Exponential backoff with full jitter: a random delay between 0 and BaseDelay * 2^(retry-1)
+	backoff := p.BaseDelay << min(retry-1, 30)
+	if backoff <= 0 {
+		backoff = p.BaseDelay << 30
+	}
+	if p.MaxDelay > 0 && backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// IsRetryable reports whether a request that failed with the given error is worth retrying.
+// Rate limits, server errors and network errors are retryable. Blocked requests,
+// missing locations and schema changes are not, as retrying them would fail the same way.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return errors.Is(statusErr, ErrRateLimited) || errors.Is(statusErr, ErrServerError)
+	}
+
+	return !errors.Is(err, ErrSchemaChanged)
+}
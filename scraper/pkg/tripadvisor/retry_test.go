@@ -0,0 +1,346 @@
+package tripadvisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rewriteTransport sends every request to the given test server instead of the real TripAdvisor endpoint
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestClient returns an HTTP client whose requests are served by the given test server
+func newTestClient(t *testing.T, server *httptest.Server) *http.Client {
+	t.Helper()
+	target, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	return &http.Client{Transport: rewriteTransport{target: target}}
+}
+
+// useRetryPolicy replaces DefaultRetryPolicy for the duration of the test
+func useRetryPolicy(t *testing.T, policy RetryPolicy) {
+	t.Helper()
+	previous := DefaultRetryPolicy
+	DefaultRetryPolicy = policy
+	t.Cleanup(func() { DefaultRetryPolicy = previous })
+}
+
+func TestMakeRequestRetries(t *testing.T) {
+	const validBody = `[{"data":{"locations":[{"locationId":1,"reviewListPage":{"totalCount":1,"reviews":[{"id":42}]}}]}}]`
+
+	tests := []struct {
+		name             string
+		statuses         []int
+		retryAfter       string
+		body             string
+		expectedAttempts int32
+		expectedErr      error
+	}{
+		{
+			name:             "success on first attempt",
+			statuses:         []int{http.StatusOK},
+			body:             validBody,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "rate limit is retried honoring Retry-After",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "0",
+			body:             validBody,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "server error is retried until success",
+			statuses:         []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			body:             validBody,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "persistent rate limit gives up after max attempts",
+			statuses:         []int{http.StatusTooManyRequests},
+			expectedAttempts: 3,
+			expectedErr:      ErrRateLimited,
+		},
+		{
+			name:             "persistent server error gives up after max attempts",
+			statuses:         []int{http.StatusInternalServerError},
+			expectedAttempts: 3,
+			expectedErr:      ErrServerError,
+		},
+		{
+			name:             "forbidden is not retried",
+			statuses:         []int{http.StatusForbidden},
+			expectedAttempts: 1,
+			expectedErr:      ErrBlocked,
+		},
+		{
+			name:             "not found is not retried",
+			statuses:         []int{http.StatusNotFound},
+			expectedAttempts: 1,
+			expectedErr:      ErrNotFound,
+		},
+		{
+			name:             "unexpected status is not retried",
+			statuses:         []int{http.StatusTeapot},
+			expectedAttempts: 1,
+			expectedErr:      ErrUnexpectedStatus,
+		},
+		{
+			name:             "undecodable body is reported as schema change",
+			statuses:         []int{http.StatusOK},
+			body:             `<html>captcha</html>`,
+			expectedAttempts: 1,
+			expectedErr:      ErrSchemaChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRetryPolicy(t, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := int(attempts.Add(1))
+				status := tt.statuses[min(attempt, len(tt.statuses))-1]
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			responses, err := MakeRequest(newTestClient(t, server), HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 0, ReviewLimit)
+
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, responses)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []Review{{ID: 42}}, ExtractReviews(responses))
+		})
+	}
+}
+
+func TestMakeRequestRetriesNetworkErrors(t *testing.T) {
+	useRetryPolicy(t, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	client := newTestClient(t, server)
+	server.Close()
+
+	_, err := MakeRequest(client, HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 0, ReviewLimit)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 2 attempts")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{
+			name:     "empty header",
+			value:    "",
+			expected: 0,
+		},
+		{
+			name:     "delay in seconds",
+			value:    "30",
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "negative seconds",
+			value:    "-5",
+			expected: 0,
+		},
+		{
+			name:     "HTTP date in the future",
+			value:    now.Add(90 * time.Second).Format(http.TimeFormat),
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "HTTP date in the past",
+			value:    now.Add(-time.Minute).Format(http.TimeFormat),
+			expected: 0,
+		},
+		{
+			name:     "garbage value",
+			value:    "soon",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		name       string
+		retry      int
+		retryAfter time.Duration
+		maxDelay   time.Duration
+	}{
+		{
+			name:     "first retry is at most the base delay",
+			retry:    1,
+			maxDelay: time.Second,
+		},
+		{
+			name:     "third retry is at most four times the base delay",
+			retry:    3,
+			maxDelay: 4 * time.Second,
+		},
+		{
+			name:     "backoff is capped by the max delay",
+			retry:    40,
+			maxDelay: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				delay := policy.Delay(tt.retry, tt.retryAfter)
+				assert.GreaterOrEqual(t, delay, time.Duration(0))
+				assert.LessOrEqual(t, delay, tt.maxDelay)
+			}
+		})
+	}
+
+	assert.Equal(t, 3*time.Second, policy.Delay(1, 3*time.Second), "Retry-After takes precedence")
+	assert.Equal(t, 10*time.Second, policy.Delay(1, time.Hour), "Retry-After is capped by the max delay")
+}
+
+func TestRetryPolicyDelayWithoutMaxDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second}
+
+	assert.Equal(t, time.Hour, policy.Delay(1, time.Hour), "Retry-After is not capped")
+
+	// With a cap of zero, every backoff would be zero. Without a cap, some of the third retries wait longer than the base delay.
+	var longest time.Duration
+	for range 100 {
+		delay := policy.Delay(3, 0)
+		assert.LessOrEqual(t, delay, 4*time.Second)
+		longest = max(longest, delay)
+	}
+	assert.Greater(t, longest, time.Second)
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "rate limited",
+			err:      &StatusError{StatusCode: http.StatusTooManyRequests, Err: ErrRateLimited},
+			expected: true,
+		},
+		{
+			name:     "server error",
+			err:      &StatusError{StatusCode: http.StatusBadGateway, Err: ErrServerError},
+			expected: true,
+		},
+		{
+			name:     "blocked",
+			err:      &StatusError{StatusCode: http.StatusForbidden, Err: ErrBlocked},
+			expected: false,
+		},
+		{
+			name:     "not found",
+			err:      &StatusError{StatusCode: http.StatusNotFound, Err: ErrNotFound},
+			expected: false,
+		},
+		{
+			name:     "schema changed",
+			err:      fmt.Errorf("%w: bad json", ErrSchemaChanged),
+			expected: false,
+		},
+		{
+			name:     "network error",
+			err:      errors.New("connection reset by peer"),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestMakeRequestWithOptionsSortBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     RequestOptions
+		expected string
+	}{
+		{
+			name:     "server determined order by default",
+			opts:     RequestOptions{},
+			expected: SortByServerDetermined,
+		},
+		{
+			name:     "newest first",
+			opts:     RequestOptions{SortBy: SortByDate},
+			expected: SortByDate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sortBy string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var batch []struct {
+					Variables struct {
+						SortBy string `json:"sortBy"`
+					} `json:"variables"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+				sortBy = batch[0].Variables.SortBy
+				fmt.Fprint(w, `[]`)
+			}))
+			defer server.Close()
+
+			_, err := MakeRequestWithOptions(newTestClient(t, server), HotelQueryID, "HOTEL", []string{"en"}, 1, 1, 0, ReviewLimit, tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, sortBy)
+		})
+	}
+}
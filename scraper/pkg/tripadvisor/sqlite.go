@@ -0,0 +1,415 @@
+package tripadvisor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	// Registers the pure Go sqlite driver, as the scraper is built without cgo
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the tables of a SQLite output. Every table is keyed by the TripAdvisor IDs,
+// so that scraping a location again updates its rows instead of duplicating them.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS locations (
+	location_id            INTEGER PRIMARY KEY,
+	name                   TEXT,
+	type                   TEXT,
+	url                    TEXT,
+	place_type             TEXT,
+	accommodation_category TEXT,
+	partial                INTEGER,
+	scraped_at             TEXT
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	user_id       TEXT PRIMARY KEY,
+	username      TEXT,
+	display_name  TEXT,
+	is_verified   INTEGER,
+	hometown      TEXT,
+	contributions INTEGER,
+	profile_url   TEXT,
+	avatar_url    TEXT
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+	review_id         INTEGER PRIMARY KEY,
+	location_id       INTEGER REFERENCES locations (location_id),
+	user_id           TEXT REFERENCES users (user_id),
+	title             TEXT,
+	text              TEXT,
+	rating            INTEGER,
+	language          TEXT,
+	status            TEXT,
+	publish_platform  TEXT,
+	created_date      TEXT,
+	published_date    TEXT,
+	stay_date         TEXT,
+	trip_type         TEXT,
+	helpful_votes     INTEGER,
+	labels            TEXT,
+	photo_ids         TEXT,
+	original_title    TEXT,
+	original_text     TEXT,
+	original_language TEXT,
+	translation_type  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS reviews_location_id ON reviews (location_id);
+
+CREATE TABLE IF NOT EXISTS review_photos (
+	photo_id     INTEGER PRIMARY KEY,
+	review_id    INTEGER REFERENCES reviews (review_id),
+	caption      TEXT,
+	url_template TEXT,
+	max_width    INTEGER,
+	max_height   INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS review_photos_review_id ON review_photos (review_id);
+
+CREATE TABLE IF NOT EXISTS management_responses (
+	review_id       INTEGER PRIMARY KEY REFERENCES reviews (review_id),
+	response_id     INTEGER,
+	text            TEXT,
+	language        TEXT,
+	published_date  TEXT,
+	responder       TEXT,
+	responder_title TEXT
+);
+
+CREATE TABLE IF NOT EXISTS review_sub_ratings (
+	review_id INTEGER REFERENCES reviews (review_id),
+	aspect    TEXT,
+	rating    INTEGER,
+	PRIMARY KEY (review_id, aspect)
+);
+
+CREATE TABLE IF NOT EXISTS review_flights (
+	review_id        INTEGER PRIMARY KEY REFERENCES reviews (review_id),
+	origin_code      TEXT,
+	origin_name      TEXT,
+	destination_code TEXT,
+	destination_name TEXT,
+	cabin_class      TEXT,
+	flight_type      TEXT,
+	aircraft         TEXT
+);
+
+CREATE TABLE IF NOT EXISTS location_keywords (
+	location_id INTEGER REFERENCES locations (location_id),
+	keyword     TEXT,
+	count       INTEGER,
+	PRIMARY KEY (location_id, keyword)
+);
+
+CREATE TABLE IF NOT EXISTS michelin_awards (
+	location_id    INTEGER REFERENCES locations (location_id),
+	award_name     TEXT,
+	award_title    TEXT,
+	year_of_award  TEXT,
+	description    TEXT,
+	award_icon_url TEXT,
+	PRIMARY KEY (location_id, award_name, year_of_award)
+);
+`
+
+// The upserts only replace the columns of a location with the non-empty values of the new row,
+// as the scrape metadata and the reviews each know part of them. Likewise, the original text of a review
+// is kept when it is scraped again without fetching it.
+const (
+	upsertLocationSQL = `
+INSERT INTO locations (location_id, name, type, url, place_type, accommodation_category)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (location_id) DO UPDATE SET
+	name = COALESCE(NULLIF(excluded.name, ''), name),
+	type = COALESCE(NULLIF(excluded.type, ''), type),
+	url = COALESCE(NULLIF(excluded.url, ''), url),
+	place_type = COALESCE(NULLIF(excluded.place_type, ''), place_type),
+	accommodation_category = COALESCE(NULLIF(excluded.accommodation_category, ''), accommodation_category)`
+
+	upsertUserSQL = `
+INSERT INTO users (user_id, username, display_name, is_verified, hometown, contributions, profile_url, avatar_url)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE SET
+	username = excluded.username,
+	display_name = excluded.display_name,
+	is_verified = excluded.is_verified,
+	hometown = excluded.hometown,
+	contributions = excluded.contributions,
+	profile_url = excluded.profile_url,
+	avatar_url = excluded.avatar_url`
+
+	upsertReviewSQL = `
+INSERT INTO reviews (review_id, location_id, user_id, title, text, rating, language, status, publish_platform,
+	created_date, published_date, stay_date, trip_type, helpful_votes, labels, photo_ids,
+	original_title, original_text, original_language, translation_type)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (review_id) DO UPDATE SET
+	location_id = excluded.location_id,
+	user_id = excluded.user_id,
+	title = excluded.title,
+	text = excluded.text,
+	rating = excluded.rating,
+	language = excluded.language,
+	status = excluded.status,
+	publish_platform = excluded.publish_platform,
+	created_date = excluded.created_date,
+	published_date = excluded.published_date,
+	stay_date = excluded.stay_date,
+	trip_type = excluded.trip_type,
+	helpful_votes = excluded.helpful_votes,
+	labels = excluded.labels,
+	photo_ids = excluded.photo_ids,
+	original_title = COALESCE(NULLIF(excluded.original_title, ''), original_title),
+	original_text = COALESCE(NULLIF(excluded.original_text, ''), original_text),
+	original_language = COALESCE(NULLIF(excluded.original_language, ''), original_language),
+	translation_type = excluded.translation_type`
+
+	upsertReviewPhotoSQL = `
+INSERT INTO review_photos (photo_id, review_id, caption, url_template, max_width, max_height)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (photo_id) DO UPDATE SET
+	review_id = excluded.review_id,
+	caption = excluded.caption,
+	url_template = excluded.url_template,
+	max_width = excluded.max_width,
+	max_height = excluded.max_height`
+
+	upsertManagementResponseSQL = `
+INSERT INTO management_responses (review_id, response_id, text, language, published_date, responder, responder_title)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (review_id) DO UPDATE SET
+	response_id = excluded.response_id,
+	text = excluded.text,
+	language = excluded.language,
+	published_date = excluded.published_date,
+	responder = excluded.responder,
+	responder_title = excluded.responder_title`
+
+	upsertReviewSubRatingSQL = `
+INSERT INTO review_sub_ratings (review_id, aspect, rating)
+VALUES (?, ?, ?)
+ON CONFLICT (review_id, aspect) DO UPDATE SET
+	rating = excluded.rating`
+
+	upsertReviewFlightSQL = `
+INSERT INTO review_flights (review_id, origin_code, origin_name, destination_code, destination_name, cabin_class, flight_type, aircraft)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (review_id) DO UPDATE SET
+	origin_code = excluded.origin_code,
+	origin_name = excluded.origin_name,
+	destination_code = excluded.destination_code,
+	destination_name = excluded.destination_name,
+	cabin_class = excluded.cabin_class,
+	flight_type = excluded.flight_type,
+	aircraft = excluded.aircraft`
+
+	upsertLocationKeywordSQL = `
+INSERT INTO location_keywords (location_id, keyword, count)
+VALUES (?, ?, ?)
+ON CONFLICT (location_id, keyword) DO UPDATE SET
+	count = excluded.count`
+
+	upsertMichelinAwardSQL = `
+INSERT INTO michelin_awards (location_id, award_name, award_title, year_of_award, description, award_icon_url)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (location_id, award_name, year_of_award) DO UPDATE SET
+	award_title = excluded.award_title,
+	description = excluded.description,
+	award_icon_url = excluded.award_icon_url`
+
+	completeLocationSQL = `UPDATE locations SET partial = ?, scraped_at = ? WHERE location_id = ?`
+)
+
+// SQLiteReviewWriter upserts the reviews, their locations, users, photos, sub-ratings, flights and management responses,
+// the keywords of its reviews and the Michelin awards into a SQLite database.
+// Each page is written in its own transaction, and the database accumulates every location scraped into it.
+// Unlike the other writers, it owns the database, which is closed by Close.
+type SQLiteReviewWriter struct {
+	db   *sql.DB
+	meta *ScrapeMetadata
+}
+
+// OpenSQLiteReviewWriter opens the SQLite database at the given path, creating it and its tables if needed
+func OpenSQLiteReviewWriter(path string) (*SQLiteReviewWriter, error) {
+	// Wait for the locations scraped in parallel in batch mode to release the database
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)", path))
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite database %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating sqlite tables: %w", err)
+	}
+
+	return &SQLiteReviewWriter{db: db}, nil
+}
+
+// Begin upserts the scraped location
+func (s *SQLiteReviewWriter) Begin(meta *ScrapeMetadata) error {
+	s.meta = meta
+	if meta.LocationID == 0 {
+		return nil
+	}
+
+	if _, err := s.db.Exec(upsertLocationSQL, meta.LocationID, meta.LocationName, string(meta.LocationType), meta.LocationURL, "", ""); err != nil {
+		return fmt.Errorf("error writing location to sqlite: %w", err)
+	}
+	return nil
+}
+
+// Write upserts the reviews and their locations, users, photos and management responses in a single transaction
+func (s *SQLiteReviewWriter) Write(reviews []Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error beginning sqlite transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, r := range reviews {
+		if err := upsertReview(tx, r, s.meta.LocationID); err != nil {
+			return fmt.Errorf("error writing review %d to sqlite: %w", r.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing sqlite transaction: %w", err)
+	}
+	return nil
+}
+
+// Close upserts the Michelin awards and the keywords, records whether the scrape of the location was partial and closes the database
+func (s *SQLiteReviewWriter) Close() error {
+	if err := s.complete(); err != nil {
+		s.db.Close()
+		return err
+	}
+
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("error closing sqlite database: %w", err)
+	}
+	return nil
+}
+
+// Discard closes the database without completing the scrape of the location, e.g. when the scrape fails before its output is completed.
+// It does nothing once the writer is closed.
+func (s *SQLiteReviewWriter) Discard() error {
+	return s.db.Close()
+}
+
+// complete writes what is only known at the end of the scrape
+func (s *SQLiteReviewWriter) complete() error {
+	if s.meta == nil || s.meta.LocationID == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error beginning sqlite transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if s.meta.Michelin != nil {
+		for _, award := range s.meta.Michelin.Awards {
+			if _, err := tx.Exec(upsertMichelinAwardSQL, s.meta.LocationID, award.AwardName, award.AwardTitle, award.YearOfAward, award.Description, award.AwardIconURL); err != nil {
+				return fmt.Errorf("error writing Michelin award to sqlite: %w", err)
+			}
+		}
+	}
+
+	for _, keyword := range s.meta.Keywords {
+		if _, err := tx.Exec(upsertLocationKeywordSQL, s.meta.LocationID, keyword.Keyword, keyword.Count); err != nil {
+			return fmt.Errorf("error writing keyword to sqlite: %w", err)
+		}
+	}
+
+	finishedAt := s.meta.FinishedAt
+	if finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+	if _, err := tx.Exec(completeLocationSQL, s.meta.Partial, finishedAt.UTC().Format(time.RFC3339), s.meta.LocationID); err != nil {
+		return fmt.Errorf("error completing location in sqlite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing sqlite transaction: %w", err)
+	}
+	return nil
+}
+
+// upsertReview upserts a review along with its location, user, photos, sub-ratings, flight and management response
+func upsertReview(tx *sql.Tx, r Review, defaultLocationID uint32) error {
+	locationID := int64(r.LocationID)
+	if locationID == 0 {
+		locationID = int64(defaultLocationID)
+	}
+
+	if r.Location.LocationID != 0 {
+		if _, err := tx.Exec(upsertLocationSQL, r.Location.LocationID, r.Location.Name, "", r.Location.URL, r.Location.PlaceType, r.Location.AccommodationCategory); err != nil {
+			return fmt.Errorf("error writing location: %w", err)
+		}
+	}
+
+	var userID any
+	if profile := r.UserProfile; profile.ID != "" {
+		userID = profile.ID
+		hometown, _ := profile.Hometown.FallbackString.(string)
+		if _, err := tx.Exec(upsertUserSQL, profile.ID, profile.Username, profile.DisplayName, profile.IsVerified, hometown,
+			profile.ContributionCounts.SumAllUgc, profile.Route.URL, profile.Avatar.Data.PhotoSizeDynamic.URLTemplate); err != nil {
+			return fmt.Errorf("error writing user: %w", err)
+		}
+	}
+
+	labels, err := json.Marshal(r.Labels)
+	if err != nil {
+		return fmt.Errorf("error marshalling labels: %w", err)
+	}
+	photoIDs, err := json.Marshal(r.PhotoIds)
+	if err != nil {
+		return fmt.Errorf("error marshalling photo IDs: %w", err)
+	}
+
+	_, err = tx.Exec(upsertReviewSQL, r.ID, locationID, userID, r.Title, r.Text, r.Rating, r.Language, r.Status, r.PublishPlatform,
+		r.CreatedDate, r.PublishedDate, r.TripInfo.StayDate, r.TripInfo.TripType, r.HelpfulVotes, string(labels), string(photoIDs),
+		r.OriginalTitle, r.OriginalText, r.OriginalLanguage, r.TranslationType)
+	if err != nil {
+		return err
+	}
+
+	for _, photo := range r.Photos {
+		dynamic := photo.PhotoSizeDynamic
+		if _, err := tx.Exec(upsertReviewPhotoSQL, photo.ID, r.ID, photo.Caption, dynamic.URLTemplate, dynamic.MaxWidth, dynamic.MaxHeight); err != nil {
+			return fmt.Errorf("error writing photo %d: %w", photo.ID, err)
+		}
+	}
+
+	for aspect, rating := range r.SubRatings {
+		if _, err := tx.Exec(upsertReviewSubRatingSQL, r.ID, aspect, rating); err != nil {
+			return fmt.Errorf("error writing %s sub-rating: %w", aspect, err)
+		}
+	}
+
+	if flight := r.Flight; flight != nil {
+		if _, err := tx.Exec(upsertReviewFlightSQL, r.ID, flight.Origin.Code, flight.Origin.Name, flight.Destination.Code, flight.Destination.Name,
+			flight.CabinClass, flight.FlightType, flight.Aircraft); err != nil {
+			return fmt.Errorf("error writing flight: %w", err)
+		}
+	}
+
+	if response := r.MgmtResponse; response != nil {
+		if _, err := tx.Exec(upsertManagementResponseSQL, r.ID, response.ID, response.Text, response.Language, response.PublishedDate,
+			response.Responder(), response.ConnectionToSubject); err != nil {
+			return fmt.Errorf("error writing management response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package tripadvisor
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sqliteCount returns the number of rows matching the query
+func sqliteCount(t *testing.T, db *sql.DB, query string, args ...any) int {
+	var count int
+	assert.NoError(t, db.QueryRow(query, args...).Scan(&count))
+	return count
+}
+
+func TestSQLiteReviewWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.sqlite")
+
+	review := func(id int, rating int, userID string) Review {
+		r := Review{ID: id, Rating: rating, Title: "Title", Text: "Text", CreatedDate: "2025-06-15", Labels: []string{"family"}, PhotoIds: []int{7}}
+		r.UserProfile.ID = userID
+		r.UserProfile.DisplayName = "User " + userID
+		r.Photos = []ReviewPhoto{{ID: id * 10, Caption: "Photo"}}
+		return r
+	}
+
+	// A first scrape of the location, interrupted after two pages
+	writer, err := OpenSQLiteReviewWriter(path)
+	assert.NoError(t, err)
+	meta := &ScrapeMetadata{
+		LocationName: "Star_Restaurant",
+		LocationID:   1751525,
+		LocationType: LocationTypeRestaurant,
+		LocationURL:  "https://www.tripadvisor.com/Restaurant_Review-g187147-d1751525-Reviews-Star_Restaurant-Paris_Ile_de_France.html",
+		Michelin:     &MichelinInfo{Awards: []MichelinAward{{AwardName: "ONE_STAR", YearOfAward: "2025"}}},
+		Keywords:     []ReviewKeyword{{Keyword: "tasting menu", Count: 5}, {Keyword: "wine", Count: 3}},
+	}
+	assert.NoError(t, writer.Begin(meta))
+	assert.NoError(t, writer.Write([]Review{review(1, 5, "A"), review(2, 4, "B")}))
+	responded := review(3, 3, "A")
+	responded.SubRatings = map[string]int{SubRatingFood: 4, SubRatingService: 2}
+	responded.Flight = &FlightInfo{Origin: Airport{Code: "GVA"}, Destination: Airport{Code: "IST"}, CabinClass: "ECONOMY"}
+	responded.MgmtResponse = &ManagementResponse{ID: 30, Text: "Thank you", PublishedDate: "2025-06-17", Username: "owner", ConnectionToSubject: "Owner"}
+	assert.NoError(t, writer.Write([]Review{responded}))
+	meta.Partial = true
+	assert.NoError(t, writer.Close())
+
+	// Scraping the location again updates the rows instead of duplicating them
+	writer, err = OpenSQLiteReviewWriter(path)
+	assert.NoError(t, err)
+	meta.Partial = false
+	assert.NoError(t, writer.Begin(meta))
+	assert.NoError(t, writer.Write([]Review{review(1, 2, "A"), review(4, 5, "C")}))
+	assert.NoError(t, writer.Close())
+
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 4, sqliteCount(t, db, "SELECT COUNT(*) FROM reviews WHERE location_id = ?", 1751525))
+	assert.Equal(t, 3, sqliteCount(t, db, "SELECT COUNT(*) FROM users"))
+	assert.Equal(t, 1, sqliteCount(t, db, "SELECT COUNT(*) FROM michelin_awards WHERE location_id = ?", 1751525))
+	assert.Equal(t, 4, sqliteCount(t, db, "SELECT COUNT(*) FROM review_photos"))
+	assert.Equal(t, 1, sqliteCount(t, db, "SELECT COUNT(*) FROM review_photos WHERE photo_id = 10 AND review_id = 1"))
+	assert.Equal(t, 2, sqliteCount(t, db, "SELECT COUNT(*) FROM review_sub_ratings WHERE review_id = 3"))
+	assert.Equal(t, 1, sqliteCount(t, db, "SELECT COUNT(*) FROM review_flights WHERE review_id = 3 AND origin_code = 'GVA' AND cabin_class = 'ECONOMY'"))
+	assert.Equal(t, 2, sqliteCount(t, db, "SELECT COUNT(*) FROM location_keywords WHERE location_id = ?", 1751525))
+	assert.Equal(t, 1, sqliteCount(t, db, "SELECT COUNT(*) FROM review_sub_ratings WHERE review_id = 3 AND aspect = 'food' AND rating = 4"))
+	assert.Equal(t, 1, sqliteCount(t, db, "SELECT COUNT(*) FROM management_responses WHERE review_id = 3 AND responder = 'owner' AND responder_title = 'Owner'"))
+
+	var rating int
+	var userID, labels string
+	assert.NoError(t, db.QueryRow("SELECT rating, user_id, labels FROM reviews WHERE review_id = 1").Scan(&rating, &userID, &labels))
+	assert.Equal(t, 2, rating)
+	assert.Equal(t, "A", userID)
+	assert.JSONEq(t, `["family"]`, labels)
+
+	var name, locationType string
+	var partial bool
+	assert.NoError(t, db.QueryRow("SELECT name, type, partial FROM locations WHERE location_id = ?", 1751525).Scan(&name, &locationType, &partial))
+	assert.Equal(t, "Star_Restaurant", name)
+	assert.Equal(t, "RESTO", locationType)
+	assert.False(t, partial)
+}
+
+func TestSQLiteReviewWriterDiscard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.sqlite")
+
+	writer, err := OpenSQLiteReviewWriter(path)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel", LocationID: 231860}))
+	assert.NoError(t, writer.Write([]Review{{ID: 1, Rating: 5}}))
+	assert.NoError(t, writer.Discard())
+
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// The written pages are kept, but the location is not marked as scraped
+	assert.Equal(t, 1, sqliteCount(t, db, "SELECT COUNT(*) FROM reviews"))
+	assert.Equal(t, 0, sqliteCount(t, db, "SELECT COUNT(*) FROM locations WHERE scraped_at IS NOT NULL"))
+}
+
+func TestSQLiteReviewWriterKeepsOriginalText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.sqlite")
+
+	writer, err := OpenSQLiteReviewWriter(path)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel", LocationID: 231860}))
+	assert.NoError(t, writer.Write([]Review{{ID: 1, Title: "Great", OriginalTitle: "Super", OriginalText: "Adoré", OriginalLanguage: "fr", TranslationType: "MACHINE"}}))
+	assert.NoError(t, writer.Close())
+
+	// Scraping again without the original text keeps it
+	writer, err = OpenSQLiteReviewWriter(path)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel", LocationID: 231860}))
+	assert.NoError(t, writer.Write([]Review{{ID: 1, Title: "Super", Language: "fr"}}))
+	assert.NoError(t, writer.Close())
+
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	var title, originalTitle, originalText, originalLanguage string
+	assert.NoError(t, db.QueryRow("SELECT title, original_title, original_text, original_language FROM reviews WHERE review_id = 1").
+		Scan(&title, &originalTitle, &originalText, &originalLanguage))
+	assert.Equal(t, "Super", title)
+	assert.Equal(t, "Super", originalTitle)
+	assert.Equal(t, "Adoré", originalText)
+	assert.Equal(t, "fr", originalLanguage)
+}
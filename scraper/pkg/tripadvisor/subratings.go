@@ -0,0 +1,148 @@
+package tripadvisor
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Aspects of the sub-ratings of hotel, restaurant and airline reviews
+const (
+	SubRatingValue                 = "value"
+	SubRatingRooms                 = "rooms"
+	SubRatingLocation              = "location"
+	SubRatingCleanliness           = "cleanliness"
+	SubRatingService               = "service"
+	SubRatingSleepQuality          = "sleepQuality"
+	SubRatingFood                  = "food"
+	SubRatingAtmosphere            = "atmosphere"
+	SubRatingLegroom               = "legroom"
+	SubRatingSeatComfort           = "seatComfort"
+	SubRatingInFlightEntertainment = "inFlightEntertainment"
+	SubRatingCustomerService       = "customerService"
+	SubRatingValueForMoney         = "valueForMoney"
+	SubRatingCheckInAndBoarding    = "checkInAndBoarding"
+	SubRatingFoodAndBeverage       = "foodAndBeverage"
+)
+
+// SubRatingAspects are the aspects known to the scraper, each of which has a column of its own in the outputs
+var SubRatingAspects = []string{
+	SubRatingValue, SubRatingRooms, SubRatingLocation, SubRatingCleanliness,
+	SubRatingService, SubRatingSleepQuality, SubRatingFood, SubRatingAtmosphere,
+	SubRatingLegroom, SubRatingSeatComfort, SubRatingInFlightEntertainment, SubRatingCustomerService,
+	SubRatingValueForMoney, SubRatingCheckInAndBoarding, SubRatingFoodAndBeverage,
+}
+
+// subRatingLabels are the names of the known aspects, used in the column headers
+var subRatingLabels = map[string]string{
+	SubRatingValue:        "Value",
+	SubRatingRooms:        "Rooms",
+	SubRatingLocation:     "Location",
+	SubRatingCleanliness:  "Cleanliness",
+	SubRatingService:      "Service",
+	SubRatingSleepQuality: "Sleep Quality",
+	SubRatingFood:         "Food",
+	SubRatingAtmosphere:   "Atmosphere",
+
+	SubRatingLegroom:               "Legroom",
+	SubRatingSeatComfort:           "Seat Comfort",
+	SubRatingInFlightEntertainment: "In-flight Entertainment",
+	SubRatingCustomerService:       "Customer Service",
+	SubRatingValueForMoney:         "Value for Money",
+	SubRatingCheckInAndBoarding:    "Check-in and Boarding",
+	SubRatingFoodAndBeverage:       "Food and Beverage",
+}
+
+// AdditionalRating is a sub-rating of a review as returned by TripAdvisor
+type AdditionalRating struct {
+	Rating      int    `json:"rating"`
+	RatingLabel string `json:"ratingLabel"`
+}
+
+// UnmarshalJSON decodes a review, turning the additional ratings returned by TripAdvisor into SubRatings
+func (r *Review) UnmarshalJSON(data []byte) error {
+	// The alias has no UnmarshalJSON method, which would otherwise be called recursively
+	type review Review
+	var decoded struct {
+		review
+		AdditionalRatings []AdditionalRating `json:"additionalRatings"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*r = Review(decoded.review)
+	for _, rating := range decoded.AdditionalRatings {
+		if rating.RatingLabel == "" || rating.Rating == 0 {
+			continue
+		}
+		if r.SubRatings == nil {
+			r.SubRatings = make(map[string]int)
+		}
+		r.SubRatings[SubRatingKey(rating.RatingLabel)] = rating.Rating
+	}
+	return nil
+}
+
+// SubRatingKey returns the aspect of a sub-rating label, such as sleepQuality for Sleep Quality.
+// Labels are localized by TripAdvisor, so only the English ones match the known aspects.
+func SubRatingKey(label string) string {
+	words := strings.FieldsFunc(label, func(c rune) bool { return !unicode.IsLetter(c) && !unicode.IsDigit(c) })
+	var key strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		key.WriteString(word)
+	}
+	return key.String()
+}
+
+// UnknownSubRatings returns the sorted aspects the reviews are rated on that are not known to the scraper, and so have no column of their own.
+// They are usually named after the label of a language other than English.
+func UnknownSubRatings(reviews []Review) []string {
+	var unknown []string
+	for _, r := range reviews {
+		for aspect := range r.SubRatings {
+			if !slices.Contains(SubRatingAspects, aspect) && !slices.Contains(unknown, aspect) {
+				unknown = append(unknown, aspect)
+			}
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+// SubRating returns the rating of the review for the aspect, or nil if the review does not rate it
+func (r Review) SubRating(aspect string) *int {
+	rating, ok := r.SubRatings[aspect]
+	if !ok {
+		return nil
+	}
+	return &rating
+}
+
+// subRatingHeader returns the column header of an aspect, such as Sleep Quality Rating
+func subRatingHeader(aspect string) string {
+	label, ok := subRatingLabels[aspect]
+	if !ok {
+		return "subRatings." + aspect
+	}
+	return label + " Rating"
+}
+
+// subRatingCSVValue returns the column value of the rating of the aspect, empty when the review does not rate it
+func subRatingCSVValue(aspect string) func(r Review, _ string, _ *MichelinInfo) string {
+	return func(r Review, _ string, _ *MichelinInfo) string {
+		rating := r.SubRating(aspect)
+		if rating == nil {
+			return ""
+		}
+		return strconv.Itoa(*rating)
+	}
+}
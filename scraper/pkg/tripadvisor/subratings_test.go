@@ -0,0 +1,92 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected map[string]int
+	}{
+		{
+			name:     "additional ratings returned by TripAdvisor",
+			body:     `{"id":1,"rating":5,"additionalRatings":[{"rating":4,"ratingLabel":"Sleep Quality"},{"rating":5,"ratingLabel":"Cleanliness"},{"rating":0,"ratingLabel":"Rooms"}]}`,
+			expected: map[string]int{SubRatingSleepQuality: 4, SubRatingCleanliness: 5},
+		},
+		{
+			name:     "sub-ratings written by the scraper",
+			body:     `{"id":1,"rating":5,"subRatings":{"food":3}}`,
+			expected: map[string]int{SubRatingFood: 3},
+		},
+		{
+			name:     "no sub-ratings",
+			body:     `{"id":1,"rating":5}`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var review Review
+			assert.NoError(t, json.Unmarshal([]byte(tt.body), &review))
+			assert.Equal(t, 1, review.ID)
+			assert.Equal(t, 5, review.Rating)
+			assert.Equal(t, tt.expected, review.SubRatings)
+		})
+	}
+}
+
+func TestSubRatingKey(t *testing.T) {
+	tests := []struct {
+		label    string
+		expected string
+	}{
+		{label: "Value", expected: SubRatingValue},
+		{label: "Sleep Quality", expected: SubRatingSleepQuality},
+		{label: "Seat comfort", expected: "seatComfort"},
+		{label: "In-flight Entertainment", expected: "inFlightEntertainment"},
+		{label: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SubRatingKey(tt.label))
+		})
+	}
+}
+
+func TestUnknownSubRatings(t *testing.T) {
+	reviews := []Review{
+		{SubRatings: map[string]int{SubRatingCleanliness: 4, "proprete": 4}},
+		{SubRatings: map[string]int{"service": 5, "emplacement": 3, "proprete": 5}},
+		{},
+	}
+
+	assert.Equal(t, []string{"emplacement", "proprete"}, UnknownSubRatings(reviews))
+	assert.Nil(t, UnknownSubRatings(reviews[:0]))
+}
+
+func TestCSVReviewWriterSubRatings(t *testing.T) {
+	review := Review{ID: 42, Rating: 5, SubRatings: map[string]int{SubRatingRooms: 4, SubRatingCleanliness: 5}}
+
+	var buf bytes.Buffer
+	writer, err := NewCSVReviewWriterWithFormat(&buf, CSVFormat{Columns: []string{
+		"rating", "subRatings." + SubRatingValue, "subRatings." + SubRatingRooms, "subRatings." + SubRatingCleanliness,
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel", LocationType: LocationTypeHotel}))
+	assert.NoError(t, writer.Write([]Review{review}))
+	assert.NoError(t, writer.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "Rating,Value Rating,Rooms Rating,Cleanliness Rating", lines[0])
+	assert.Equal(t, "5,,4,5", lines[1])
+}
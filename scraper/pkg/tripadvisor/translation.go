@@ -0,0 +1,22 @@
+package tripadvisor
+
+import "cmp"
+
+// MergeOriginalText sets the original title, text and language of the reviews from the same reviews fetched without machine translation,
+// matched by ID. The reviews without a match are left untouched.
+func MergeOriginalText(reviews []Review, originals []Review) {
+	byID := make(map[int]Review, len(originals))
+	for _, original := range originals {
+		byID[original.ID] = original
+	}
+
+	for i := range reviews {
+		original, ok := byID[reviews[i].ID]
+		if !ok {
+			continue
+		}
+		reviews[i].OriginalTitle = original.Title
+		reviews[i].OriginalText = original.Text
+		reviews[i].OriginalLanguage = cmp.Or(reviews[i].OriginalLanguage, original.OriginalLanguage, original.Language)
+	}
+}
@@ -0,0 +1,90 @@
+package tripadvisor
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeOriginalText(t *testing.T) {
+	reviews := []Review{
+		{ID: 1, Title: "Great", Text: "Loved it", Language: "en", OriginalLanguage: "fr", TranslationType: "MACHINE"},
+		{ID: 2, Title: "Fine", Text: "It was fine", Language: "en"},
+		{ID: 3, Title: "Missing", Text: "Not in the originals", Language: "en"},
+	}
+	originals := []Review{
+		{ID: 2, Title: "Fine", Text: "It was fine", Language: "en"},
+		{ID: 1, Title: "Super", Text: "Adoré", Language: "fr"},
+	}
+
+	MergeOriginalText(reviews, originals)
+
+	assert.Equal(t, []Review{
+		{ID: 1, Title: "Great", Text: "Loved it", Language: "en", OriginalLanguage: "fr", TranslationType: "MACHINE", OriginalTitle: "Super", OriginalText: "Adoré"},
+		{ID: 2, Title: "Fine", Text: "It was fine", Language: "en", OriginalLanguage: "en", OriginalTitle: "Fine", OriginalText: "It was fine"},
+		{ID: 3, Title: "Missing", Text: "Not in the originals", Language: "en"},
+	}, reviews)
+}
+
+// translationServer returns a server answering with a French review, translated into English when machine translation is requested.
+// It records the doMachineTranslation variable of every request.
+func translationServer(t *testing.T, translations *[]bool) *httptest.Server {
+	return newGraphQLServer(t, func(batch []graphQLRequest) reviewListPage {
+		translate := batch[0].Variables.DoMachineTranslation
+		*translations = append(*translations, translate)
+
+		review := Review{ID: 1, Title: "Super", Text: "Adoré", Language: "fr"}
+		if translate {
+			review = Review{ID: 1, Title: "Great", Text: "Loved it", Language: "en", OriginalLanguage: "fr", TranslationType: "MACHINE"}
+		}
+		return reviewListPage{TotalCount: 1, Reviews: []Review{review}}
+	})
+}
+
+func TestClientReviewsOriginalText(t *testing.T) {
+	location := &Location{Type: LocationTypeHotel, GeoID: 1, LocationID: 1}
+
+	tests := []struct {
+		name                 string
+		opts                 ReviewsOptions
+		expectedTranslations []bool
+		expected             []Review
+	}{
+		{
+			name:                 "translated by default",
+			opts:                 ReviewsOptions{},
+			expectedTranslations: []bool{true},
+			expected:             []Review{{ID: 1, Title: "Great", Text: "Loved it", Language: "en", OriginalLanguage: "fr", TranslationType: "MACHINE"}},
+		},
+		{
+			name:                 "original text fetched along with the translation",
+			opts:                 ReviewsOptions{OriginalText: true},
+			expectedTranslations: []bool{true, false},
+			expected: []Review{{ID: 1, Title: "Great", Text: "Loved it", Language: "en", OriginalLanguage: "fr", TranslationType: "MACHINE",
+				OriginalTitle: "Super", OriginalText: "Adoré"}},
+		},
+		{
+			name:                 "machine translation disabled",
+			opts:                 ReviewsOptions{OriginalText: true, Request: RequestOptions{DisableMachineTranslation: true}},
+			expectedTranslations: []bool{false},
+			expected:             []Review{{ID: 1, Title: "Super", Text: "Adoré", Language: "fr"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var translations []bool
+			server := translationServer(t, &translations)
+			defer server.Close()
+
+			client := NewClient(WithBaseURL(server.URL), WithLogger(nil))
+			responses, err := client.Reviews(context.Background(), location, tt.opts)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTranslations, translations)
+			assert.Equal(t, tt.expected, ExtractReviews(responses))
+		})
+	}
+}
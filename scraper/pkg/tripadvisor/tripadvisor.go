@@ -1,210 +1,150 @@
 package tripadvisor
 
 import (
-	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"os"
+	"slices"
 	"sort"
-	"strconv"
-	"strings"
 	"time"
-
-	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/utils"
 )
 
-// MakeRequest is a function that sends a POST request to the TripAdvisor GraphQL endpoint
-func MakeRequest(client *http.Client, queryID string, queryType string, language []string, locationID uint32, geoId uint32, offset uint32, limit uint32) (responses *Responses, err error) {
+// RequestOptions holds the optional settings of a review request
+type RequestOptions struct {
+	// SortBy is the order in which reviews are returned. Defaults to SortByServerDetermined.
+	// Airline reviews can not be sorted and ignore it.
+	SortBy string
 
-	/*
-	* Prepare the request body
-	 */
-	requestFilter := Filter{
-		Axis:       "LANGUAGE",
-		Selections: language,
-	}
+	// Domain is the TripAdvisor domain, such as www.tripadvisor.fr, whose GraphQL endpoint the request is sent to.
+	// Defaults to DefaultDomain.
+	Domain string
 
-	requestExtensions := Extensions{
-		PreRegisteredQueryID: queryID,
-	}
+	// DisableMachineTranslation returns the title and text of the reviews as written. By default, TripAdvisor
+	// machine translates the reviews written in another language into the first requested language.
+	DisableMachineTranslation bool
 
-	var request any
-
-	if queryType == "AIRLINE" {
-		request = BatchRequests{{
-			Variables: AirlineVariables{
-				LocationID:     locationID,
-				Offset:         offset,
-				Filters:        Filters{requestFilter},
-				Limit:          limit,
-				NeedKeywords:   true,
-				PrefsCacheKey:  fmt.Sprintf("locationReviewPrefs_%d", locationID),
-				KeywordVariant: "location_keywords_v2_llr_order_30_en",
-				InitialPrefs:   struct{}{},
-				FilterCacheKey: nil,
-				Prefs:          nil,
-			},
-			Extensions: requestExtensions,
-		}}
-
-	} else {
-		requestVariables := Variables{
-			LocationID:           locationID,
-			Offset:               offset,
-			Filters:              Filters{requestFilter},
-			Limit:                limit,
-			SortType:             nil,
-			SortBy:               "SERVER_DETERMINED",
-			Language:             language[0],
-			DoMachineTranslation: true,
-			PhotosPerReviewLimit: 7,
-		}
-
-		routeOffsets := []any{0} // first: number 0
-		for i := uint32(1); i <= 7; i++ {
-			routeOffsets = append(routeOffsets, fmt.Sprintf("r%d", i*ReviewLimit)) // rest: "r10", "r20"...
-		}
+	// Filters narrows down the reviews on top of their language
+	Filters ReviewFilters
 
-		var pageName string
-		switch queryType {
-		case "HOTEL":
-			pageName = "Hotel_Review"
-		case "ATTRACTION":
-			pageName = "Attraction_Review"
-		case "RESTO":
-			pageName = "Restaurant_Review"
-		case "AIRLINE":
-			pageName = "Airline_Review"
-		}
+	// PhotosPerReview is the maximum number of photos returned with each review. Defaults to DefaultPhotosPerReview.
+	PhotosPerReview uint32
+}
 
-		var routes []RouteRequest
-		for _, off := range routeOffsets {
-			routes = append(routes, RouteRequest{
-				Fragment: "",
-				Page:     pageName, // adjust based on queryType
-				Params: RouteParams{
-					GeoID:    geoId, // you'll need geoId passed in or parsed from URL
-					DetailID: locationID,
-					Offset:   off,
-				},
-			})
-		}
+// MakeRequest is a function that sends a POST request to the TripAdvisor GraphQL endpoint
+func MakeRequest(client *http.Client, queryID string, queryType string, language []string, locationID uint32, geoId uint32, offset uint32, limit uint32) (responses *Responses, err error) {
+	return MakeRequestWithOptions(client, queryID, queryType, language, locationID, geoId, offset, limit, RequestOptions{})
+}
 
-		if queryType == "RESTO" {
-			// Batch both into a single request array
-			request = BatchRequests{
-				{
-					Variables:  requestVariables,
-					Extensions: requestExtensions,
-				},
-				{
-					Variables:  RoutesVariables{RoutesRequest: routes},
-					Extensions: Extensions{PreRegisteredQueryID: queryID},
-				},
-				// Query for fetching Michelin reviews for restaurants
-				{
-					Variables:  MichelinVariables{IDs: []uint32{locationID}},
-					Extensions: Extensions{PreRegisteredQueryID: MichelinQueryID},
-				},
-			}
-		} else {
-			// Batch both into a single request array
-			request = BatchRequests{
-				{
-					Variables:  requestVariables,
-					Extensions: requestExtensions,
-				},
-				{
-					Variables:  RoutesVariables{RoutesRequest: routes},
-					Extensions: Extensions{PreRegisteredQueryID: queryID},
-				},
-			}
-		}
-	}
-	// Marshal the request body into JSON
-	jsonPayload, err := json.Marshal(request)
-	if err != nil {
-		log.Fatal("error marshalling request body: ", err)
+// MakeRequestWithOptions is like MakeRequest but applies the given request options
+func MakeRequestWithOptions(client *http.Client, queryID string, queryType string, language []string, locationID uint32, geoId uint32, offset uint32, limit uint32, opts RequestOptions) (responses *Responses, err error) {
+	query := ReviewsQuery{
+		QueryID:    queryID,
+		Languages:  language,
+		LocationID: locationID,
+		GeoID:      geoId,
+		Offset:     offset,
+		Limit:      limit,
 	}
+	return NewClient(WithHTTPClient(client)).makeRequest(context.Background(), LocationType(queryType), query, opts)
+}
 
-	// Create a new request using http.NewRequest, setting the method to POST
-	req, err := http.NewRequest(http.MethodPost, EndPointURL, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
+// buildAirlineReviewsRequest builds the body of a request for a page of airline reviews
+func buildAirlineReviewsRequest(query ReviewsQuery) BatchRequests {
+	return BatchRequests{{
+		Variables: AirlineVariables{
+			LocationID:     query.LocationID,
+			Offset:         query.Offset,
+			Filters:        query.Filters.Filters(query.Languages),
+			Limit:          query.Limit,
+			NeedKeywords:   true,
+			PrefsCacheKey:  fmt.Sprintf("locationReviewPrefs_%d", query.LocationID),
+			KeywordVariant: "location_keywords_v2_llr_order_30_en",
+			InitialPrefs:   struct{}{},
+			FilterCacheKey: nil,
+			Prefs:          nil,
+		},
+		Extensions: Extensions{PreRegisteredQueryID: query.QueryID},
+	}}
+}
 
-	requestedById, err := utils.GenerateRequestedByID()
-	if err != nil {
-		return nil, fmt.Errorf("error generating X-Requested-By ID: %w", err)
-	}
+// buildRestaurantReviewsRequest builds the body of a request for a page of restaurant reviews, along with their Michelin Star status
+func buildRestaurantReviewsRequest(query ReviewsQuery) BatchRequests {
+	return append(buildLocationReviewsRequest(query), BatchRequest{
+		// Query for fetching Michelin reviews for restaurants
+		Variables:  MichelinVariables{IDs: []uint32{query.LocationID}},
+		Extensions: Extensions{PreRegisteredQueryID: MichelinQueryID},
+	})
+}
 
-	// Set the necessary headers as per the original Axios request
-	req.Header.Set("Host", "www.tripadvisor.com")
-	req.Header.Set("Origin", "https://www.tripadvisor.com")
-	req.Header.Set("Referer", "https://www.tripadvisor.com/Hotels")
-	req.Header.Set("Pragma", "no-cache")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 11_0_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/87.0.4280.101 Safari/537.36")
-	req.Header.Set("X-Requested-By", requestedById)
-	req.Header.Set("Cookie", fmt.Sprintf("TAUnique=%s", requestedById))
-	req.Header.Set("Content-Type", "application/json;charset=utf-8")
-	req.Header.Set("Accepted-Encoding", "gzip, deflate, br")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-
-	// Send the request
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
-	}
-	defer resp.Body.Close()
+// buildLocationReviewsRequest builds the body of a request for a page of reviews of a location within a geographic area, such as a hotel
+func buildLocationReviewsRequest(query ReviewsQuery) BatchRequests {
 
-	// Check the response status code
-	if resp.StatusCode != http.StatusOK {
-		// Check for rate limiting
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, fmt.Errorf("rate Limit Detected: %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = SortByServerDetermined
 	}
 
-	// Read the response body
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+	requestVariables := Variables{
+		LocationID:           query.LocationID,
+		Offset:               query.Offset,
+		Filters:              query.Filters.Filters(query.Languages),
+		Limit:                query.Limit,
+		SortType:             nil,
+		SortBy:               sortBy,
+		Language:             query.Languages[0],
+		DoMachineTranslation: !query.DisableMachineTranslation,
+		PhotosPerReviewLimit: cmp.Or(query.PhotosPerReview, DefaultPhotosPerReview),
 	}
 
-	// Marshal the response body into the Response struct
-	responseData := Responses{}
-
-	err = json.Unmarshal(responseBody, &responseData)
+	routeOffsets := []any{0} // first: number 0
+	for i := uint32(1); i <= 7; i++ {
+		routeOffsets = append(routeOffsets, fmt.Sprintf("r%d", i*ReviewLimit)) // rest: "r10", "r20"...
+	}
 
-	// Check for errors
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %w", err)
+	var routes []RouteRequest
+	for _, off := range routeOffsets {
+		routes = append(routes, RouteRequest{
+			Fragment: "",
+			Page:     query.PageName,
+			Params: RouteParams{
+				GeoID:    query.GeoID,
+				DetailID: query.LocationID,
+				Offset:   off,
+			},
+		})
 	}
 
-	if os.Getenv("DEBUG") == "true" {
-		fmt.Printf("Raw respsone:\n%s\n", string(responseBody))
+	// Batch both into a single request array
+	return BatchRequests{
+		{
+			Variables:  requestVariables,
+			Extensions: Extensions{PreRegisteredQueryID: query.QueryID},
+		},
+		{
+			Variables:  RoutesVariables{RoutesRequest: routes},
+			Extensions: Extensions{PreRegisteredQueryID: query.QueryID},
+		},
 	}
+}
 
-	return &responseData, err
+// languageFilter returns the filter selecting the reviews in the given languages
+func languageFilter(languages []string) Filter {
+	return Filter{
+		Axis:       FilterAxisLanguage,
+		Selections: languages,
+	}
 }
 
-// GetQueryID is a function that returns the query ID for the given query type
-func GetQueryID(queryType string) (queryID string) {
-
-	switch queryType {
-	case "HOTEL":
-		return HotelQueryID
-	case "AIRLINE":
-		return AirlineQueryID
-	case "ATTRACTION":
-		return AttractionQueryID
-	default:
-		return HotelQueryID
+// GetQueryID is a function that returns the query ID for the given query type, or an error for unknown types
+func GetQueryID(queryType string) (queryID string, err error) {
+	descriptor, err := LookupLocationType(LocationType(queryType))
+	if err != nil {
+		return "", err
 	}
+	return descriptor.QueryID, nil
 }
 
 // ExtractReviews extracts the review slice from API responses,
@@ -262,55 +202,52 @@ func ExtractMichelinInfo(responses *Responses) *MichelinInfo {
 	return nil
 }
 
-// CSVHeaders returns the CSV column headers.
+// CSVHeaders returns the headers of the DefaultCSVColumns.
 // When includeMichelin is true, Michelin award columns are appended.
 func CSVHeaders(includeMichelin bool) []string {
-	headers := []string{"Location Name", "Title", "Text", "Rating", "Year", "Month", "Day", "Trip Type", "Stay Date"}
-	if includeMichelin {
-		headers = append(headers, "Michelin Award", "Michelin Year")
+	columns := legacyCSVColumns(includeMichelin)
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, column.header)
 	}
 	return headers
 }
 
-// ReviewToCSVRow converts a single Review into a CSV row.
+// ReviewToCSVRow converts a single Review into a CSV row of the DefaultCSVColumns.
 // When michelin is non-nil, Michelin award columns are appended.
 func ReviewToCSVRow(r Review, locationName string, michelin *MichelinInfo) []string {
-	row := []string{
-		locationName,
-		r.Title,
-		r.Text,
-		strconv.Itoa(r.Rating),
-		r.CreatedDate[0:4],
-		r.CreatedDate[5:7],
-		r.CreatedDate[8:10],
-		r.TripInfo.TripType,
-		r.TripInfo.StayDate,
+	return csvRow(legacyCSVColumns(michelin != nil), r, locationName, michelin)
+}
+
+// legacyCSVColumns returns the compiled DefaultCSVColumns, followed by the MichelinCSVColumns if asked to
+func legacyCSVColumns(includeMichelin bool) []csvColumn {
+	if includeMichelin {
+		return compileDefaultCSVColumns(slices.Concat(DefaultCSVColumns, MichelinCSVColumns))
 	}
-	if michelin != nil {
-		names := make([]string, 0, len(michelin.Awards))
-		years := make([]string, 0, len(michelin.Awards))
-		for _, a := range michelin.Awards {
-			names = append(names, a.AwardName)
-			years = append(years, a.YearOfAward)
-		}
-		row = append(row, strings.Join(names, "; "), strings.Join(years, "; "))
+	return compileDefaultCSVColumns(DefaultCSVColumns)
+}
+
+// csvRow converts a single Review into a CSV row of the given columns
+func csvRow(columns []csvColumn, r Review, locationName string, michelin *MichelinInfo) []string {
+	row := make([]string, 0, len(columns))
+	for _, column := range columns {
+		row = append(row, column.value(r, locationName, michelin))
 	}
 	return row
 }
 
 // FetchReviewCount fetches the review count for the given location ID and query type.
 func FetchReviewCount(client *http.Client, locationID uint32, geoID uint32, queryType string, languages []string) (int, error) {
+	return FetchReviewCountWithOptions(client, locationID, geoID, queryType, languages, RequestOptions{})
+}
 
-	// Get the query ID for the given query type.
-	queryID := GetQueryID(queryType)
-
-	// Make the request to the TripAdvisor GraphQL endpoint.
-	responses, err := MakeRequest(client, queryID, queryType, languages, locationID, geoID, 0, 1)
+// FetchReviewCountWithOptions is like FetchReviewCount but applies the given request options, such as the filters
+func FetchReviewCountWithOptions(client *http.Client, locationID uint32, geoID uint32, queryType string, languages []string, opts RequestOptions) (int, error) {
+	location := &Location{Type: LocationType(queryType), LocationID: locationID, GeoID: geoID}
+	count, err := NewClient(WithHTTPClient(client)).CountReviews(context.Background(), location, ReviewsOptions{Languages: languages, Request: opts})
 	if err != nil {
-		return 0, fmt.Errorf("error making request: %w", err)
+		return 0, err
 	}
-
-	count := ExtractTotalCount(responses)
 	if count == 0 {
 		return 0, fmt.Errorf("no reviews found for location ID %d", locationID)
 	}
@@ -341,69 +278,21 @@ func CalculateOffset(iteration uint32) (offset uint32) {
 
 // GetURLType is a function that validates the URL and returns the type of URL
 func GetURLType(url string) string {
-	if tripAdvisorHotelURLRegexp.MatchString(url) {
-		return "HOTEL"
-	}
-
-	if tripAdvisorRestaurantRegexp.MatchString(url) {
-		return "RESTO"
-	}
-
-	if tripAdvisorAirlineRegexp.MatchString(url) {
-		return "AIRLINE"
-	}
-
-	if tripAdvisorAttractionRegexp.MatchString(url) {
-		return "ATTRACTION"
+	location, err := ParseLocation(url, "")
+	if err != nil {
+		return ""
 	}
-
-	return ""
+	return string(location.Type)
 }
 
 // ParseURL is a function that parses the URL and returns the location ID and the location name
+// It is a shorthand for ParseLocation, which also returns the slug and the canonical URL of the location.
 func ParseURL(url string, locationType string) (locationID uint32, geoID uint32, locationName string, error error) {
-	// Sample hotel url: https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html
-	// Sample restaurant url: https://www.tripadvisor.com/Restaurant_Review-g187265-d11827759-Reviews-La_Terrasse-Lyon_Rhone_Auvergne_Rhone_Alpes.html
-	// Sample airline url: https://www.tripadvisor.com/Airline_Review-d8728979-Reviews-Pegasus-Airlines
-	// Sample attraction url: https://www.tripadvisor.com/Attraction_Review-g187261-d195616-Reviews-Mont_Blanc-Chamonix_Haute_Savoie_Auvergne_Rhone_Alpes.html
-
-	switch locationType {
-
-	case "HOTEL", "RESTO", "ATTRACTION":
-
-		// Split the URL by -
-		urlSplit := strings.Split(url, "-")
-
-		// Trim the d from the location ID
-		locationID, err := strconv.ParseUint(strings.TrimLeft(urlSplit[2], "d"), 10, 32)
-		if err != nil {
-			return 0, 0, "", fmt.Errorf("error parsing location ID: %w", err)
-		}
-
-		geoID, err := strconv.ParseUint(strings.TrimLeft(urlSplit[1], "g"), 10, 32)
-		if err != nil {
-			return 0, 0, "", fmt.Errorf("error parsing geo ID: %w", err)
-		}
-
-		// Extract the location name from the URL
-		locationName = urlSplit[4]
-
-		return uint32(locationID), uint32(geoID), locationName, nil
-
-	case "AIRLINE":
-
-		urlSplit := strings.Split(url, "-")
-		locationID, err := strconv.ParseUint(strings.TrimLeft(urlSplit[1], "d"), 10, 32)
-		if err != nil {
-			return 0, 0, "", fmt.Errorf("error parsing location ID: %w", err)
-		}
-
-		locationName = strings.Join(urlSplit[3:], "_")
-
-		return uint32(locationID), 0, locationName, nil
-	default:
-		return 0, 0, "", fmt.Errorf("invalid location type: %s", locationType)
+	location, err := ParseLocation(url, LocationType(locationType))
+	if err != nil {
+		return 0, 0, "", err
 	}
+	return location.LocationID, location.GeoID, location.Name, nil
 }
 
 // WriteScrapeResultToJSONFile writes a ScrapeResult (reviews + optional Michelin data) to a JSON file.
@@ -48,8 +48,28 @@ func TestGetURLType(t *testing.T) {
 			expected: "",
 		},
 		{
-			name:     "tripadvisor .fr domain returns empty",
+			name:     "tripadvisor .fr domain",
 			url:      "https://www.tripadvisor.fr/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+			expected: "HOTEL",
+		},
+		{
+			name:     "tripadvisor .co.uk domain",
+			url:      "https://www.tripadvisor.co.uk/Restaurant_Review-g187265-d11827759-Reviews-La_Terrasse-Lyon_Rhone_Auvergne_Rhone_Alpes.html",
+			expected: "RESTO",
+		},
+		{
+			name:     "tripadvisor .com.au domain",
+			url:      "https://www.tripadvisor.com.au/Airline_Review-d8729113-Reviews-Lufthansa",
+			expected: "AIRLINE",
+		},
+		{
+			name:     "tripadvisor language subdomain",
+			url:      "https://fr.tripadvisor.ch/Attraction_Review-g187261-d1008501-Reviews-Les_Ailes_du_Mont_Blanc-Chamonix_Haute_Savoie_Auvergne_Rhone_Alpes.html",
+			expected: "ATTRACTION",
+		},
+		{
+			name:     "lookalike domain returns empty",
+			url:      "https://www.tripadvisor.com.example.org/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
 			expected: "",
 		},
 	}
@@ -112,6 +132,22 @@ func TestParseURL(t *testing.T) {
 			expectedGeoID:   0,
 			expectedLocName: "Pegasus_Airlines",
 		},
+		{
+			name:            "parse hotel URL on a localized domain",
+			url:             "https://www.tripadvisor.de/Hotel_Review-g188107-d231860-Reviews-Beau_Rivage_Palace-Lausanne_Canton_of_Vaud.html",
+			locationType:    "HOTEL",
+			expectedLocID:   231860,
+			expectedGeoID:   188107,
+			expectedLocName: "Beau_Rivage_Palace",
+		},
+		{
+			name:            "parse airline URL on a localized domain",
+			url:             "https://www.tripadvisor.co.uk/Airline_Review-d8728979-Reviews-Pegasus-Airlines",
+			locationType:    "AIRLINE",
+			expectedLocID:   8728979,
+			expectedGeoID:   0,
+			expectedLocName: "Pegasus_Airlines",
+		},
 		{
 			name:         "invalid location type returns error",
 			url:          "https://www.tripadvisor.com/SomethingElse",
@@ -137,9 +173,10 @@ func TestParseURL(t *testing.T) {
 
 func TestGetQueryID(t *testing.T) {
 	tests := []struct {
-		name      string
-		queryType string
-		expected  string
+		name        string
+		queryType   string
+		expected    string
+		expectError bool
 	}{
 		{
 			name:      "hotel returns hotel query ID",
@@ -157,20 +194,31 @@ func TestGetQueryID(t *testing.T) {
 			expected:  AttractionQueryID,
 		},
 		{
-			name:      "unknown type defaults to hotel query ID",
-			queryType: "UNKNOWN",
-			expected:  HotelQueryID,
+			name:      "restaurant returns restaurant query ID",
+			queryType: "RESTO",
+			expected:  RestaurantQueryID,
 		},
 		{
-			name:      "empty string defaults to hotel query ID",
-			queryType: "",
-			expected:  HotelQueryID,
+			name:        "unknown type is rejected",
+			queryType:   "UNKNOWN",
+			expectError: true,
+		},
+		{
+			name:        "empty string is rejected",
+			queryType:   "",
+			expectError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GetQueryID(tt.queryType)
+			result, err := GetQueryID(tt.queryType)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrUnknownLocationType)
+				assert.Empty(t, result)
+				return
+			}
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
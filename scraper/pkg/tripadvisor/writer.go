@@ -0,0 +1,428 @@
+package tripadvisor
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ScrapeMetadata describes the scraped location to the review writers
+type ScrapeMetadata struct {
+	LocationName string
+	LocationID   uint32
+	LocationType LocationType
+	LocationURL  string
+	Michelin     *MichelinInfo
+
+	// Keywords are the keywords of the reviews, returned with the reviews of airlines
+	Keywords []ReviewKeyword
+
+	// Partial is set when the scrape was interrupted before every review was fetched
+	Partial bool
+
+	// FinishedAt is the time the scrape ended, set before the writer is closed
+	FinishedAt time.Time
+}
+
+// writerOptions holds the settings of the review writers
+type writerOptions struct {
+	metadataLine bool
+	csvFormat    CSVFormat
+}
+
+// WriterOption configures the ReviewWriter returned by NewReviewWriter
+type WriterOption func(*writerOptions)
+
+// WithMetadataLine makes the NDJSON writer end the output with a line holding the scrape metadata. Other formats ignore it.
+func WithMetadataLine() WriterOption {
+	return func(o *writerOptions) {
+		o.metadataLine = true
+	}
+}
+
+// WithCSVFormat sets the columns and the dialect of the CSV writer. Other formats ignore it.
+func WithCSVFormat(format CSVFormat) WriterOption {
+	return func(o *writerOptions) {
+		o.csvFormat = format
+	}
+}
+
+// ReviewWriter streams reviews to an output as pages are fetched, so that the whole scrape never has to be held in memory.
+type ReviewWriter interface {
+	// Begin writes whatever precedes the first review, such as the CSV header.
+	// The writer keeps the metadata, and changes made to it before Close are reflected in formats that write metadata last.
+	Begin(meta *ScrapeMetadata) error
+
+	// Write writes a page of reviews and flushes it to the underlying writer
+	Write(reviews []Review) error
+
+	// Close writes whatever follows the last review and flushes the underlying writer.
+	// It does not close the underlying writer.
+	Close() error
+}
+
+// NewReviewWriter returns the ReviewWriter for the given file type.
+// When resume is a checkpoint with completed pages, the writer continues the output left behind by that run instead of starting a new one.
+func NewReviewWriter(fileType string, w io.Writer, resume *Checkpoint, opts ...WriterOption) (ReviewWriter, error) {
+	resumed := resume != nil && resume.Started()
+
+	var options writerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	switch fileType {
+	case "csv":
+		writer, err := NewCSVReviewWriterWithFormat(w, options.csvFormat)
+		if err != nil {
+			return nil, err
+		}
+		writer.resumed = resumed
+		return writer, nil
+	case "json":
+		writer := NewJSONReviewWriter(w)
+		writer.resumed = resumed
+		if resumed {
+			writer.count = resume.ReviewsWritten
+		}
+		return writer, nil
+	case "parquet":
+		if resumed {
+			return nil, fmt.Errorf("resuming a parquet output is not supported")
+		}
+		return NewParquetReviewWriter(w), nil
+	case "ndjson":
+		writer := NewNDJSONReviewWriter(w)
+		writer.metadataLine = options.metadataLine
+		if resumed {
+			writer.count = resume.ReviewsWritten
+			writer.responses = resume.Responses
+		}
+		return writer, nil
+	case "xlsx":
+		if resumed {
+			return nil, fmt.Errorf("resuming a xlsx output is not supported")
+		}
+		return NewXLSXReviewWriter(w), nil
+	case "sqlite":
+		return nil, fmt.Errorf("a sqlite output is a database rather than a stream. Use OpenSQLiteReviewWriter")
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s", fileType)
+	}
+}
+
+// CanResume reports whether the output of the given file type can be continued by a later run, see NewReviewWriter
+func CanResume(fileType string) bool {
+	return fileType != "parquet" && fileType != "xlsx"
+}
+
+// CSVReviewWriter writes reviews as CSV rows, using the columns and the dialect of its CSVFormat
+type CSVReviewWriter struct {
+	out     *bufio.Writer
+	writer  *csv.Writer
+	format  CSVFormat
+	columns []csvColumn
+	meta    *ScrapeMetadata
+	resumed bool
+}
+
+// NewCSVReviewWriter returns a CSVReviewWriter writing the DefaultCSVColumns to w
+func NewCSVReviewWriter(w io.Writer) *CSVReviewWriter {
+	// The default format is always valid
+	writer, _ := NewCSVReviewWriterWithFormat(w, CSVFormat{})
+	return writer
+}
+
+// NewCSVReviewWriterWithFormat returns a CSVReviewWriter writing to w in the given format.
+// It returns an error wrapping ErrUnknownCSVColumn if a column can not be resolved.
+func NewCSVReviewWriterWithFormat(w io.Writer, format CSVFormat) (*CSVReviewWriter, error) {
+	c := &CSVReviewWriter{out: bufio.NewWriter(w), format: format}
+	c.writer = csv.NewWriter(c.out)
+	if format.Delimiter != 0 {
+		c.writer.Comma = format.Delimiter
+	}
+
+	if len(format.Columns) > 0 {
+		columns, err := compileCSVColumns(format.Columns)
+		if err != nil {
+			return nil, err
+		}
+		c.columns = columns
+	}
+	return c, nil
+}
+
+// Begin writes the byte order mark if asked to and the CSV header.
+// The default columns include the Michelin columns when Michelin data is present.
+func (c *CSVReviewWriter) Begin(meta *ScrapeMetadata) error {
+	c.meta = meta
+	if c.columns == nil {
+		c.columns = legacyCSVColumns(meta.Michelin != nil)
+	}
+	if c.resumed {
+		return nil
+	}
+
+	if c.format.BOM {
+		if _, err := c.out.WriteString("\uFEFF"); err != nil {
+			return fmt.Errorf("error writing byte order mark to csv: %w", err)
+		}
+	}
+
+	headers := make([]string, 0, len(c.columns))
+	for _, column := range c.columns {
+		headers = append(headers, column.header)
+	}
+	if err := c.writeRecord(headers); err != nil {
+		return fmt.Errorf("error writing header to csv: %w", err)
+	}
+	return c.flush()
+}
+
+// Write writes one CSV row per review
+func (c *CSVReviewWriter) Write(reviews []Review) error {
+	for _, r := range reviews {
+		if err := c.writeRecord(csvRow(c.columns, r, c.meta.LocationName, c.meta.Michelin)); err != nil {
+			return fmt.Errorf("error writing data to csv: %w", err)
+		}
+	}
+	return c.flush()
+}
+
+// Close flushes any buffered rows
+func (c *CSVReviewWriter) Close() error {
+	return c.flush()
+}
+
+// writeRecord writes a CSV record, quoting every field if asked to.
+// encoding/csv only quotes the fields that need it, so quoted records are written directly.
+func (c *CSVReviewWriter) writeRecord(fields []string) error {
+	if !c.format.QuoteAll {
+		return c.writer.Write(fields)
+	}
+
+	delimiter := cmp.Or(c.format.Delimiter, ',')
+	for i, field := range fields {
+		if i > 0 {
+			if _, err := c.out.WriteRune(delimiter); err != nil {
+				return err
+			}
+		}
+		if _, err := c.out.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`); err != nil {
+			return err
+		}
+	}
+	return c.out.WriteByte('\n')
+}
+
+func (c *CSVReviewWriter) flush() error {
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("error flushing csv: %w", err)
+	}
+	if err := c.out.Flush(); err != nil {
+		return fmt.Errorf("error flushing csv: %w", err)
+	}
+	return nil
+}
+
+// JSONReviewWriter writes a ScrapeResult document, streaming the reviews array as pages arrive.
+// The Michelin data, the keywords and the partial flag are written after the reviews, when the writer is closed.
+type JSONReviewWriter struct {
+	writer  *bufio.Writer
+	meta    *ScrapeMetadata
+	count   int
+	resumed bool
+}
+
+// NewJSONReviewWriter returns a JSONReviewWriter writing to w
+func NewJSONReviewWriter(w io.Writer) *JSONReviewWriter {
+	return &JSONReviewWriter{writer: bufio.NewWriter(w)}
+}
+
+// Begin opens the JSON document and its reviews array
+func (j *JSONReviewWriter) Begin(meta *ScrapeMetadata) error {
+	j.meta = meta
+	if j.resumed {
+		return nil
+	}
+
+	if _, err := j.writer.WriteString("{\n  \"reviews\": ["); err != nil {
+		return fmt.Errorf("could not write data to file: %w", err)
+	}
+	return j.flush()
+}
+
+// Write appends the reviews to the reviews array
+func (j *JSONReviewWriter) Write(reviews []Review) error {
+	for _, r := range reviews {
+		data, err := json.MarshalIndent(r, "    ", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling review %d: %w", r.ID, err)
+		}
+
+		separator := ",\n    "
+		if j.count == 0 {
+			separator = "\n    "
+		}
+
+		if _, err := j.writer.WriteString(separator); err != nil {
+			return fmt.Errorf("could not write data to file: %w", err)
+		}
+		if _, err := j.writer.Write(data); err != nil {
+			return fmt.Errorf("could not write data to file: %w", err)
+		}
+		j.count++
+	}
+	return j.flush()
+}
+
+// Close closes the reviews array, writes the Michelin data, the keywords and the partial flag if any and closes the JSON document
+func (j *JSONReviewWriter) Close() error {
+	if _, err := j.writer.WriteString("\n  ]"); err != nil {
+		return fmt.Errorf("could not write data to file: %w", err)
+	}
+
+	if j.meta != nil && j.meta.Michelin != nil {
+		data, err := json.MarshalIndent(j.meta.Michelin, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling Michelin data: %w", err)
+		}
+		if _, err := fmt.Fprintf(j.writer, ",\n  \"michelin\": %s", data); err != nil {
+			return fmt.Errorf("could not write data to file: %w", err)
+		}
+	}
+
+	if j.meta != nil && len(j.meta.Keywords) > 0 {
+		data, err := json.MarshalIndent(j.meta.Keywords, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling keywords: %w", err)
+		}
+		if _, err := fmt.Fprintf(j.writer, ",\n  \"keywords\": %s", data); err != nil {
+			return fmt.Errorf("could not write data to file: %w", err)
+		}
+	}
+
+	if j.meta != nil && j.meta.Partial {
+		if _, err := j.writer.WriteString(",\n  \"partial\": true"); err != nil {
+			return fmt.Errorf("could not write data to file: %w", err)
+		}
+	}
+
+	if _, err := j.writer.WriteString("\n}\n"); err != nil {
+		return fmt.Errorf("could not write data to file: %w", err)
+	}
+	return j.flush()
+}
+
+func (j *JSONReviewWriter) flush() error {
+	if err := j.writer.Flush(); err != nil {
+		return fmt.Errorf("error flushing json: %w", err)
+	}
+	return nil
+}
+
+// NDJSONReviewWriter writes one JSON review object per line, so that the output can be processed line by line as it grows.
+// With WithMetadataLine, the output ends with an NDJSONMetadata line written when the writer is closed.
+type NDJSONReviewWriter struct {
+	writer       *bufio.Writer
+	meta         *ScrapeMetadata
+	count        int
+	responses    ResponseStats
+	metadataLine bool
+}
+
+// NDJSONMetadata is the trailing line of an NDJSON output. Its single "_metadata" key tells it apart from the review lines.
+type NDJSONMetadata struct {
+	Metadata NDJSONMetadataFields `json:"_metadata"`
+}
+
+// NDJSONMetadataFields describes the scraped location and the scrape in the trailing line of an NDJSON output
+type NDJSONMetadataFields struct {
+	LocationName string          `json:"locationName"`
+	LocationID   uint32          `json:"locationId,omitempty"`
+	LocationType LocationType    `json:"locationType,omitempty"`
+	LocationURL  string          `json:"locationUrl,omitempty"`
+	Michelin     *MichelinInfo   `json:"michelin,omitempty"`
+	Keywords     []ReviewKeyword `json:"keywords,omitempty"`
+	ReviewCount  int             `json:"reviewCount"`
+	Partial      bool            `json:"partial"`
+	ScrapedAt    string          `json:"scrapedAt,omitempty"`
+
+	// ResponseRate is the share of the reviews with a management response, and MedianResponseDays the median number of days
+	// the management took to respond
+	ResponseRate       float64  `json:"responseRate"`
+	MedianResponseDays *float64 `json:"medianResponseDays,omitempty"`
+}
+
+// NewNDJSONReviewWriter returns a NDJSONReviewWriter writing to w
+func NewNDJSONReviewWriter(w io.Writer) *NDJSONReviewWriter {
+	return &NDJSONReviewWriter{writer: bufio.NewWriter(w)}
+}
+
+// Begin keeps the metadata. Nothing precedes the first review.
+func (n *NDJSONReviewWriter) Begin(meta *ScrapeMetadata) error {
+	n.meta = meta
+	return nil
+}
+
+// Write writes one line per review
+func (n *NDJSONReviewWriter) Write(reviews []Review) error {
+	for _, r := range reviews {
+		if err := n.writeLine(r); err != nil {
+			return fmt.Errorf("error writing review %d: %w", r.ID, err)
+		}
+		n.count++
+	}
+	n.responses.Add(reviews)
+	return n.flush()
+}
+
+// Close writes the metadata line if asked to and flushes the underlying writer
+func (n *NDJSONReviewWriter) Close() error {
+	if n.metadataLine && n.meta != nil {
+		fields := NDJSONMetadataFields{
+			LocationName:       n.meta.LocationName,
+			LocationID:         n.meta.LocationID,
+			LocationType:       n.meta.LocationType,
+			LocationURL:        n.meta.LocationURL,
+			Michelin:           n.meta.Michelin,
+			Keywords:           n.meta.Keywords,
+			ReviewCount:        n.count,
+			Partial:            n.meta.Partial,
+			ResponseRate:       n.responses.ResponseRate(),
+			MedianResponseDays: n.responses.MedianResponseDays(),
+		}
+		if !n.meta.FinishedAt.IsZero() {
+			fields.ScrapedAt = n.meta.FinishedAt.UTC().Format(time.RFC3339)
+		}
+		if err := n.writeLine(NDJSONMetadata{Metadata: fields}); err != nil {
+			return fmt.Errorf("error writing metadata: %w", err)
+		}
+	}
+	return n.flush()
+}
+
+// writeLine writes the value as a single line of JSON
+func (n *NDJSONReviewWriter) writeLine(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if _, err := n.writer.Write(data); err != nil {
+		return err
+	}
+	return n.writer.WriteByte('\n')
+}
+
+func (n *NDJSONReviewWriter) flush() error {
+	if err := n.writer.Flush(); err != nil {
+		return fmt.Errorf("error flushing ndjson: %w", err)
+	}
+	return nil
+}
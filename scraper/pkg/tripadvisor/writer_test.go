@@ -0,0 +1,357 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReviewWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileType    string
+		expectError bool
+	}{
+		{
+			name:     "csv writer",
+			fileType: "csv",
+		},
+		{
+			name:     "json writer",
+			fileType: "json",
+		},
+		{
+			name:     "ndjson writer",
+			fileType: "ndjson",
+		},
+		{
+			name:     "parquet writer",
+			fileType: "parquet",
+		},
+		{
+			name:        "unsupported file type",
+			fileType:    "xml",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := NewReviewWriter(tt.fileType, &bytes.Buffer{}, nil)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, writer)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, writer)
+		})
+	}
+}
+
+func TestCSVReviewWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		meta     *ScrapeMetadata
+		columns  []string
+		pages    [][]Review
+		expected string
+	}{
+		{
+			name:     "header only when there are no reviews",
+			meta:     &ScrapeMetadata{LocationName: "Test_Hotel"},
+			pages:    nil,
+			expected: "Location Name,Title,Text,Rating,Year,Month,Day,Trip Type,Stay Date\n",
+		},
+		{
+			name: "rows are written page by page",
+			meta: &ScrapeMetadata{LocationName: "Test_Hotel"},
+			pages: [][]Review{
+				{{Title: "Great", Text: "Loved it", Rating: 5, CreatedDate: "2025-06-15"}},
+				{{Title: "Bad", Text: "Noisy, dirty", Rating: 1, CreatedDate: "2024-01-02"}},
+			},
+			expected: "Location Name,Title,Text,Rating,Year,Month,Day,Trip Type,Stay Date\n" +
+				"Test_Hotel,Great,Loved it,5,2025,06,15,,\n" +
+				"Test_Hotel,Bad,\"Noisy, dirty\",1,2024,01,02,,\n",
+		},
+		{
+			name:    "management response columns are opt-in",
+			meta:    &ScrapeMetadata{LocationName: "Test_Hotel"},
+			columns: []string{"title", "mgmtResponse.text", "mgmtResponse.responder", "mgmtResponse.connectionToSubject", "mgmtResponse.publishedDate", "mgmtResponse.language"},
+			pages: [][]Review{
+				{{Title: "Great", Text: "Loved it", Rating: 5, CreatedDate: "2025-06-15", MgmtResponse: &ManagementResponse{
+					Text: "Thank you", Language: "en", PublishedDate: "2025-06-17", Username: "gm123", ConnectionToSubject: "General Manager",
+				}}},
+			},
+			expected: "Title,Response Text,Responder,Responder Title,Response Date,Response Language\n" +
+				"Great,Thank you,gm123,General Manager,2025-06-17,en\n",
+		},
+		{
+			name: "Michelin columns are included when Michelin data is present",
+			meta: &ScrapeMetadata{
+				LocationName: "Star_Restaurant",
+				Michelin:     &MichelinInfo{Awards: []MichelinAward{{AwardName: "1 Star", YearOfAward: "2024"}}},
+			},
+			pages: [][]Review{
+				{{Title: "Amazing", Text: "Best meal", Rating: 5, CreatedDate: "2025-01-10"}},
+			},
+			expected: "Location Name,Title,Text,Rating,Year,Month,Day,Trip Type,Stay Date,Michelin Award,Michelin Year\n" +
+				"Star_Restaurant,Amazing,Best meal,5,2025,01,10,,,1 Star,2024\n",
+		},
+		{
+			name:    "original text columns are opt-in",
+			meta:    &ScrapeMetadata{LocationName: "Test_Hotel"},
+			columns: []string{"title", "originalTitle", "originalText", "originalLanguage"},
+			pages: [][]Review{
+				{{Title: "Great", Text: "Loved it", Rating: 5, CreatedDate: "2025-06-15", OriginalTitle: "Super", OriginalText: "Adoré", OriginalLanguage: "fr"}},
+			},
+			expected: "Title,Original Title,Original Text,Original Language\n" +
+				"Great,Super,Adoré,fr\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewCSVReviewWriterWithFormat(&buf, CSVFormat{Columns: tt.columns})
+			assert.NoError(t, err)
+
+			assert.NoError(t, writer.Begin(tt.meta))
+			for _, page := range tt.pages {
+				assert.NoError(t, writer.Write(page))
+			}
+			assert.NoError(t, writer.Close())
+
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestJSONReviewWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		meta  *ScrapeMetadata
+		pages [][]Review
+	}{
+		{
+			name:  "no reviews",
+			meta:  &ScrapeMetadata{LocationName: "Test_Hotel"},
+			pages: nil,
+		},
+		{
+			name: "reviews across several pages",
+			meta: &ScrapeMetadata{LocationName: "Test_Hotel"},
+			pages: [][]Review{
+				{{ID: 1, Title: "Great"}, {ID: 2, Title: "Good"}},
+				{},
+				{{ID: 3, Title: "Bad"}},
+			},
+		},
+		{
+			name: "Michelin data is written after the reviews",
+			meta: &ScrapeMetadata{
+				LocationName: "Star_Restaurant",
+				Michelin:     &MichelinInfo{AwardHeader: "MICHELIN Guide", Awards: []MichelinAward{{AwardName: "1 Star"}}},
+			},
+			pages: [][]Review{
+				{{ID: 1, Title: "Amazing"}},
+			},
+		},
+		{
+			name: "airline keywords are written after the reviews",
+			meta: &ScrapeMetadata{
+				LocationName: "Swiss",
+				LocationType: LocationTypeAirline,
+				Keywords:     []ReviewKeyword{{Keyword: "legroom", Count: 12}},
+			},
+			pages: [][]Review{
+				{{ID: 1, Title: "On time", Flight: &FlightInfo{Origin: Airport{Code: "GVA"}, Destination: Airport{Code: "ZRH"}, FlightType: FlightTypeDomestic}}},
+			},
+		},
+		{
+			name: "interrupted scrape is marked as partial",
+			meta: &ScrapeMetadata{
+				LocationName: "Star_Restaurant",
+				Michelin:     &MichelinInfo{AwardHeader: "MICHELIN Guide"},
+				Partial:      true,
+			},
+			pages: [][]Review{
+				{{ID: 1, Title: "Amazing"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := NewJSONReviewWriter(&buf)
+
+			assert.NoError(t, writer.Begin(tt.meta))
+			expected := ScrapeResult{Reviews: []Review{}, Michelin: tt.meta.Michelin, Keywords: tt.meta.Keywords, Partial: tt.meta.Partial}
+			for _, page := range tt.pages {
+				assert.NoError(t, writer.Write(page))
+				expected.Reviews = append(expected.Reviews, page...)
+			}
+			assert.NoError(t, writer.Close())
+
+			var result ScrapeResult
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &result), buf.String())
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestJSONReviewWriterResume(t *testing.T) {
+	var buf bytes.Buffer
+	meta := &ScrapeMetadata{LocationName: "Test_Hotel"}
+
+	// First run, interrupted after the first page
+	first, err := NewReviewWriter("json", &buf, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, first.Begin(meta))
+	assert.NoError(t, first.Write([]Review{{ID: 1}, {ID: 2}}))
+
+	// Second run, resuming from the checkpoint
+	checkpoint := NewCheckpoint(CheckpointSettings{LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html", Languages: []string{"en"}, FileType: "json"})
+	checkpoint.Record(0, 2, int64(buf.Len()), nil)
+
+	second, err := NewReviewWriter("json", &buf, checkpoint)
+	assert.NoError(t, err)
+	assert.NoError(t, second.Begin(meta))
+	assert.NoError(t, second.Write([]Review{{ID: 3}}))
+	assert.NoError(t, second.Close())
+
+	var result ScrapeResult
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result), buf.String())
+	assert.Equal(t, []Review{{ID: 1}, {ID: 2}, {ID: 3}}, result.Reviews)
+}
+
+func TestCSVReviewWriterResume(t *testing.T) {
+	var buf bytes.Buffer
+	meta := &ScrapeMetadata{LocationName: "Test_Hotel"}
+
+	checkpoint := NewCheckpoint(CheckpointSettings{LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html", Languages: []string{"en"}, FileType: "csv"})
+	checkpoint.Record(0, 20, 1024, nil)
+
+	writer, err := NewReviewWriter("csv", &buf, checkpoint)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Begin(meta))
+	assert.NoError(t, writer.Write([]Review{{Title: "Great", Text: "Loved it", Rating: 5, CreatedDate: "2025-06-15"}}))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, "Test_Hotel,Great,Loved it,5,2025,06,15,,\n", buf.String(), "the header is not written again")
+}
+
+func TestNDJSONReviewWriter(t *testing.T) {
+	finishedAt := time.Date(2025, 6, 15, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		opts             []WriterOption
+		meta             *ScrapeMetadata
+		pages            [][]Review
+		expectedMetadata string
+	}{
+		{
+			name: "no reviews",
+			meta: &ScrapeMetadata{LocationName: "Test_Hotel"},
+		},
+		{
+			name: "one line per review across several pages",
+			meta: &ScrapeMetadata{LocationName: "Test_Hotel"},
+			pages: [][]Review{
+				{{ID: 1, Title: "Great"}, {ID: 2, Title: "Good"}},
+				{},
+				{{ID: 3, Title: "Bad"}},
+			},
+		},
+		{
+			name: "metadata line is written last",
+			opts: []WriterOption{WithMetadataLine()},
+			meta: &ScrapeMetadata{
+				LocationName: "Star_Restaurant",
+				LocationID:   1751525,
+				LocationType: LocationTypeRestaurant,
+				LocationURL:  "https://www.tripadvisor.com/Restaurant_Review-g187147-d1751525-Reviews-Star_Restaurant-Paris.html",
+				Michelin:     &MichelinInfo{AwardHeader: "MICHELIN Guide"},
+				Partial:      true,
+				FinishedAt:   finishedAt,
+			},
+			pages: [][]Review{
+				{
+					{ID: 1, Title: "Amazing", PublishedDate: "2025-06-01", MgmtResponse: &ManagementResponse{Text: "Thank you", PublishedDate: "2025-06-04"}},
+					{ID: 2, Title: "Good"},
+				},
+			},
+			expectedMetadata: `{"_metadata":{"locationName":"Star_Restaurant","locationId":1751525,"locationType":"RESTO",` +
+				`"locationUrl":"https://www.tripadvisor.com/Restaurant_Review-g187147-d1751525-Reviews-Star_Restaurant-Paris.html",` +
+				`"michelin":{"awardHeader":"MICHELIN Guide"},"reviewCount":2,"partial":true,"scrapedAt":"2025-06-15T12:30:00Z",` +
+				`"responseRate":0.5,"medianResponseDays":3}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewReviewWriter("ndjson", &buf, nil, tt.opts...)
+			assert.NoError(t, err)
+
+			assert.NoError(t, writer.Begin(tt.meta))
+			var expected []Review
+			for _, page := range tt.pages {
+				assert.NoError(t, writer.Write(page))
+				expected = append(expected, page...)
+			}
+			assert.NoError(t, writer.Close())
+
+			var lines []string
+			if buf.Len() > 0 {
+				lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			}
+			if tt.expectedMetadata != "" {
+				assert.NotEmpty(t, lines)
+				assert.JSONEq(t, tt.expectedMetadata, lines[len(lines)-1])
+				lines = lines[:len(lines)-1]
+			}
+
+			var reviews []Review
+			for _, line := range lines {
+				var review Review
+				assert.NoError(t, json.Unmarshal([]byte(line), &review), line)
+				reviews = append(reviews, review)
+			}
+			assert.Equal(t, expected, reviews)
+		})
+	}
+}
+
+func TestNDJSONReviewWriterResume(t *testing.T) {
+	var buf bytes.Buffer
+	meta := &ScrapeMetadata{LocationName: "Test_Hotel"}
+
+	// First run, interrupted after the first page
+	first, err := NewReviewWriter("ndjson", &buf, nil, WithMetadataLine())
+	assert.NoError(t, err)
+	assert.NoError(t, first.Begin(meta))
+	assert.NoError(t, first.Write([]Review{{ID: 1}, {ID: 2}}))
+
+	// Second run, resuming from the checkpoint
+	checkpoint := NewCheckpoint(CheckpointSettings{LocationURL: "https://www.tripadvisor.com/Hotel_Review-g188107-d231860-Reviews-Test.html", Languages: []string{"en"}, FileType: "ndjson"})
+	checkpoint.Responses.Add([]Review{{ID: 1}, {ID: 2, MgmtResponse: &ManagementResponse{}}})
+	checkpoint.Record(0, 2, int64(buf.Len()), nil)
+
+	second, err := NewReviewWriter("ndjson", &buf, checkpoint, WithMetadataLine())
+	assert.NoError(t, err)
+	assert.NoError(t, second.Begin(meta))
+	assert.NoError(t, second.Write([]Review{{ID: 3}}))
+	assert.NoError(t, second.Close())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 4)
+	assert.JSONEq(t, `{"_metadata":{"locationName":"Test_Hotel","reviewCount":3,"partial":false,"responseRate":0.3333333333333333}}`, lines[3],
+		"the review count and response rate include the reviews of the first run")
+}
@@ -0,0 +1,382 @@
+package tripadvisor
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets of an XLSX output
+const (
+	XLSXReviewsSheet  = "Reviews"
+	XLSXMichelinSheet = "Michelin"
+	XLSXKeywordsSheet = "Keywords"
+	XLSXSummarySheet  = "Summary"
+)
+
+// xlsxReviewHeaders are the columns of the reviews sheet that come before the sub-ratings
+var xlsxReviewHeaders = []string{"Location Name", "Review ID", "Title", "Text", "Rating", "Language", "Created Date", "Published Date", "Trip Type", "Stay Date", "Helpful Votes", "User", "Original Title", "Original Text", "Original Language", "Response Text", "Responder", "Responder Title", "Response Date", "Response Language", "Origin", "Destination", "Cabin Class", "Flight Type", "Aircraft"}
+
+// XLSXHeaders are the columns of the reviews sheet of an XLSX output, ending with a column per known sub-rating aspect
+var XLSXHeaders = append(slices.Clone(xlsxReviewHeaders), subRatingHeaders()...)
+
+// xlsxSubRatingColumn is the first sub-rating column of the reviews sheet
+var xlsxSubRatingColumn = len(xlsxReviewHeaders) + 1
+
+// subRatingHeaders returns the headers of the SubRatingAspects
+func subRatingHeaders() []string {
+	headers := make([]string, 0, len(SubRatingAspects))
+	for _, aspect := range SubRatingAspects {
+		headers = append(headers, subRatingHeader(aspect))
+	}
+	return headers
+}
+
+// xlsxDateLayout is the number format of the date cells
+const xlsxDateLayout = "yyyy-mm-dd"
+
+// ReviewToXLSXRow converts a single Review into a row of the reviews sheet, keeping the numbers and dates typed.
+// Dates that can not be parsed are kept as text.
+func ReviewToXLSXRow(r Review, locationName string) []any {
+	var response ManagementResponse
+	if r.MgmtResponse != nil {
+		response = *r.MgmtResponse
+	}
+	var flight FlightInfo
+	if r.Flight != nil {
+		flight = *r.Flight
+	}
+
+	row := []any{
+		locationName,
+		r.ID,
+		r.Title,
+		r.Text,
+		r.Rating,
+		r.Language,
+		xlsxDate(r.CreatedDate),
+		xlsxDate(r.PublishedDate),
+		r.TripInfo.TripType,
+		xlsxDate(r.TripInfo.StayDate),
+		r.HelpfulVotes,
+		r.UserProfile.DisplayName,
+		r.OriginalTitle,
+		r.OriginalText,
+		r.OriginalLanguage,
+		response.Text,
+		response.Responder(),
+		response.ConnectionToSubject,
+		xlsxDate(response.PublishedDate),
+		response.Language,
+		flight.Origin.Code,
+		flight.Destination.Code,
+		flight.CabinClass,
+		flight.FlightType,
+		flight.Aircraft,
+	}
+
+	// An aspect the review does not rate is an empty cell
+	for _, aspect := range SubRatingAspects {
+		if rating := r.SubRating(aspect); rating != nil {
+			row = append(row, *rating)
+		} else {
+			row = append(row, nil)
+		}
+	}
+	return row
+}
+
+// xlsxDate returns the date as a time for a date cell, the raw value if it is not a date, or nil for an empty cell
+func xlsxDate(value string) any {
+	if value == "" {
+		return nil
+	}
+	if date := parseReviewDate(value); date != nil {
+		return *date
+	}
+	return value
+}
+
+// XLSXReviewWriter writes an Excel workbook with the reviews on a first sheet, streamed as pages arrive,
+// then the Michelin awards, the keywords of the reviews and a summary with the rating distribution on sheets written when the writer is closed.
+// A workbook is only readable once it is completed, so an output left behind by a killed run can not be resumed.
+type XLSXReviewWriter struct {
+	w           io.Writer
+	file        *excelize.File
+	stream      *excelize.StreamWriter
+	meta        *ScrapeMetadata
+	row         int
+	ratings     [6]int
+	responses   ResponseStats
+	headerStyle int
+}
+
+// NewXLSXReviewWriter returns a XLSXReviewWriter writing to w
+func NewXLSXReviewWriter(w io.Writer) *XLSXReviewWriter {
+	return &XLSXReviewWriter{w: w, file: excelize.NewFile()}
+}
+
+// Begin creates the reviews sheet with its frozen header row
+func (x *XLSXReviewWriter) Begin(meta *ScrapeMetadata) error {
+	x.meta = meta
+
+	if err := x.file.SetSheetName("Sheet1", XLSXReviewsSheet); err != nil {
+		return fmt.Errorf("error creating xlsx sheet: %w", err)
+	}
+
+	styles, err := x.newStyles()
+	if err != nil {
+		return err
+	}
+	x.headerStyle = styles.header
+
+	stream, err := x.file.NewStreamWriter(XLSXReviewsSheet)
+	if err != nil {
+		return fmt.Errorf("error creating xlsx stream writer: %w", err)
+	}
+	x.stream = stream
+
+	// The column layout must be set before the first row is written
+	columns := []struct {
+		min, max int
+		width    float64
+		style    int
+	}{
+		{1, 2, 20, 0},
+		{3, 3, 40, styles.wrapped},
+		{4, 4, 80, styles.wrapped},
+		{5, 6, 10, 0},
+		{7, 8, 14, styles.date},
+		{9, 9, 14, 0},
+		{10, 10, 14, styles.date},
+		{11, 11, 14, 0},
+		{12, 12, 20, 0},
+		{13, 13, 40, styles.wrapped},
+		{14, 14, 80, styles.wrapped},
+		{15, 15, 10, 0},
+		{16, 16, 80, styles.wrapped},
+		{17, 18, 20, 0},
+		{19, 19, 14, styles.date},
+		{20, 20, 10, 0},
+		{21, xlsxSubRatingColumn - 1, 14, 0},
+		{xlsxSubRatingColumn, xlsxSubRatingColumn + len(SubRatingAspects) - 1, 12, 0},
+	}
+	for _, column := range columns {
+		if err := stream.SetColWidth(column.min, column.max, column.width); err != nil {
+			return fmt.Errorf("error setting xlsx column width: %w", err)
+		}
+		if column.style != 0 {
+			if err := stream.SetColStyle(column.min, column.max, column.style); err != nil {
+				return fmt.Errorf("error setting xlsx column style: %w", err)
+			}
+		}
+	}
+	if err := stream.SetPanes(frozenHeaderPanes()); err != nil {
+		return fmt.Errorf("error freezing xlsx header: %w", err)
+	}
+
+	header := make([]any, 0, len(XLSXHeaders))
+	for _, name := range XLSXHeaders {
+		header = append(header, excelize.Cell{StyleID: styles.header, Value: name})
+	}
+	return x.writeRow(header)
+}
+
+// Write appends one row per review to the reviews sheet
+func (x *XLSXReviewWriter) Write(reviews []Review) error {
+	for _, r := range reviews {
+		if err := x.writeRow(ReviewToXLSXRow(r, x.meta.LocationName)); err != nil {
+			return fmt.Errorf("error writing review %d: %w", r.ID, err)
+		}
+		if r.Rating >= 1 && r.Rating <= 5 {
+			x.ratings[r.Rating]++
+		}
+	}
+	x.responses.Add(reviews)
+	return nil
+}
+
+// Close completes the reviews sheet, adds the Michelin, keywords and summary sheets and writes the workbook
+func (x *XLSXReviewWriter) Close() error {
+	defer x.file.Close()
+
+	if x.stream != nil {
+		if err := x.stream.Flush(); err != nil {
+			return fmt.Errorf("error flushing xlsx reviews sheet: %w", err)
+		}
+	}
+
+	if x.meta != nil && x.meta.Michelin != nil {
+		if err := x.writeMichelinSheet(); err != nil {
+			return err
+		}
+	}
+
+	if x.meta != nil && len(x.meta.Keywords) > 0 {
+		if err := x.writeKeywordsSheet(); err != nil {
+			return err
+		}
+	}
+
+	if x.meta != nil {
+		if err := x.writeSummarySheet(); err != nil {
+			return err
+		}
+	}
+
+	if err := x.file.Write(x.w); err != nil {
+		return fmt.Errorf("error writing xlsx: %w", err)
+	}
+	return nil
+}
+
+// xlsxStyles are the IDs of the cell styles of the workbook
+type xlsxStyles struct {
+	header  int
+	wrapped int
+	date    int
+}
+
+func (x *XLSXReviewWriter) newStyles() (xlsxStyles, error) {
+	var styles xlsxStyles
+	var err error
+
+	styles.header, err = x.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return styles, fmt.Errorf("error creating xlsx style: %w", err)
+	}
+
+	styles.wrapped, err = x.file.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"}})
+	if err != nil {
+		return styles, fmt.Errorf("error creating xlsx style: %w", err)
+	}
+
+	dateLayout := xlsxDateLayout
+	styles.date, err = x.file.NewStyle(&excelize.Style{CustomNumFmt: &dateLayout, Alignment: &excelize.Alignment{Vertical: "top"}})
+	if err != nil {
+		return styles, fmt.Errorf("error creating xlsx style: %w", err)
+	}
+
+	return styles, nil
+}
+
+// writeRow writes the next row of the reviews sheet
+func (x *XLSXReviewWriter) writeRow(values []any) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+// writeMichelinSheet writes one row per Michelin award
+func (x *XLSXReviewWriter) writeMichelinSheet() error {
+	rows := [][]any{{"Award", "Title", "Year", "Description"}}
+	for _, award := range x.meta.Michelin.Awards {
+		rows = append(rows, []any{award.AwardName, award.AwardTitle, award.YearOfAward, award.Description})
+	}
+	return x.writeSheet(XLSXMichelinSheet, rows, []float64{20, 30, 10, 80})
+}
+
+// writeKeywordsSheet writes one row per keyword of the reviews
+func (x *XLSXReviewWriter) writeKeywordsSheet() error {
+	rows := [][]any{{"Keyword", "Reviews"}}
+	for _, keyword := range x.meta.Keywords {
+		rows = append(rows, []any{keyword.Keyword, keyword.Count})
+	}
+	return x.writeSheet(XLSXKeywordsSheet, rows, []float64{30, 10})
+}
+
+// writeSummarySheet writes the location, the scrape stats, the management response figures and the rating distribution
+func (x *XLSXReviewWriter) writeSummarySheet() error {
+	count := 0
+	total := 0
+	for rating, reviews := range x.ratings {
+		count += reviews
+		total += rating * reviews
+	}
+
+	var averageRating any
+	if count > 0 {
+		averageRating = float64(total) / float64(count)
+	}
+
+	var medianResponseDays any
+	if days := x.responses.MedianResponseDays(); days != nil {
+		medianResponseDays = *days
+	}
+
+	var scrapedAt any
+	if !x.meta.FinishedAt.IsZero() {
+		scrapedAt = x.meta.FinishedAt.UTC().Format(time.RFC3339)
+	}
+
+	rows := [][]any{
+		{"Field", "Value"},
+		{"Location Name", x.meta.LocationName},
+		{"Location ID", x.meta.LocationID},
+		{"Location Type", string(x.meta.LocationType)},
+		{"Location URL", x.meta.LocationURL},
+		{"Reviews", count},
+		{"Average Rating", averageRating},
+		{"Responses", x.responses.Responded},
+		{"Response Rate", x.responses.ResponseRate()},
+		{"Median Response Days", medianResponseDays},
+		{"Partial", x.meta.Partial},
+		{"Scraped At", scrapedAt},
+		{},
+		{"Rating", "Reviews"},
+	}
+	for rating := 5; rating >= 1; rating-- {
+		rows = append(rows, []any{rating, x.ratings[rating]})
+	}
+	return x.writeSheet(XLSXSummarySheet, rows, []float64{20, 60})
+}
+
+// writeSheet writes a small sheet whose first row is a frozen header
+func (x *XLSXReviewWriter) writeSheet(sheet string, rows [][]any, widths []float64) error {
+	if _, err := x.file.NewSheet(sheet); err != nil {
+		return fmt.Errorf("error creating xlsx sheet %s: %w", sheet, err)
+	}
+
+	for i, width := range widths {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err := x.file.SetColWidth(sheet, column, column, width); err != nil {
+			return fmt.Errorf("error setting xlsx column width: %w", err)
+		}
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := x.file.SetSheetRow(sheet, cell, &row); err != nil {
+			return fmt.Errorf("error writing xlsx sheet %s: %w", sheet, err)
+		}
+	}
+
+	lastHeader, err := excelize.CoordinatesToCellName(len(rows[0]), 1)
+	if err != nil {
+		return err
+	}
+	if err := x.file.SetCellStyle(sheet, "A1", lastHeader, x.headerStyle); err != nil {
+		return fmt.Errorf("error setting xlsx header style: %w", err)
+	}
+	if err := x.file.SetPanes(sheet, frozenHeaderPanes()); err != nil {
+		return fmt.Errorf("error freezing xlsx header: %w", err)
+	}
+	return nil
+}
+
+// frozenHeaderPanes freezes the first row of a sheet
+func frozenHeaderPanes() *excelize.Panes {
+	return &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}
+}
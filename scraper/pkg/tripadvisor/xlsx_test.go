@@ -0,0 +1,155 @@
+package tripadvisor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReviewToXLSXRow(t *testing.T) {
+	review := Review{
+		ID:               42,
+		Title:            "Great",
+		Text:             "Loved it.\nWould come back",
+		Rating:           5,
+		Language:         "en",
+		CreatedDate:      "2025-06-15",
+		PublishedDate:    "not a date",
+		HelpfulVotes:     3,
+		OriginalTitle:    "Super",
+		OriginalText:     "Adoré.\nOn reviendra",
+		OriginalLanguage: "fr",
+		SubRatings:       map[string]int{SubRatingRooms: 4, SubRatingSleepQuality: 5, SubRatingLegroom: 2},
+		Flight: &FlightInfo{
+			Origin:      Airport{Code: "GVA"},
+			Destination: Airport{Code: "IST"},
+			CabinClass:  "ECONOMY",
+			FlightType:  FlightTypeInternational,
+		},
+		MgmtResponse: &ManagementResponse{
+			Text:                "Thank you",
+			Language:            "en",
+			PublishedDate:       "2025-06-17",
+			Username:            "Manager",
+			ConnectionToSubject: "General Manager",
+		},
+	}
+	review.TripInfo.TripType = "FAMILY"
+	review.UserProfile.DisplayName = "Jane D"
+
+	expected := []any{
+		"Test_Hotel", 42, "Great", "Loved it.\nWould come back", 5, "en",
+		time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC), "not a date", "FAMILY", nil, 3, "Jane D",
+		"Super", "Adoré.\nOn reviendra", "fr",
+		"Thank you", "Manager", "General Manager", time.Date(2025, 6, 17, 0, 0, 0, 0, time.UTC), "en",
+		"GVA", "IST", "ECONOMY", FlightTypeInternational, "",
+		nil, 4, nil, nil, nil, 5, nil, nil,
+		2, nil, nil, nil, nil, nil, nil,
+	}
+	assert.Equal(t, expected, ReviewToXLSXRow(review, "Test_Hotel"))
+}
+
+func TestXLSXReviewWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewReviewWriter("xlsx", &buf, nil)
+	assert.NoError(t, err)
+
+	meta := &ScrapeMetadata{
+		LocationName: "Star_Restaurant",
+		LocationID:   1751525,
+		LocationType: LocationTypeRestaurant,
+		Michelin:     &MichelinInfo{Awards: []MichelinAward{{AwardName: "ONE_STAR", YearOfAward: "2025"}}},
+	}
+	assert.NoError(t, writer.Begin(meta))
+	assert.NoError(t, writer.Write([]Review{{ID: 1, Rating: 5, Title: "A", Text: "Line 1\nLine 2", CreatedDate: "2025-06-15"}, {ID: 2, Rating: 4, CreatedDate: "2025-06-14", MgmtResponse: &ManagementResponse{PublishedDate: "2025-06-16"}}}))
+	assert.NoError(t, writer.Write([]Review{{ID: 3, Rating: 5, CreatedDate: "2025-06-13"}}))
+	meta.Partial = true
+	assert.NoError(t, writer.Close())
+
+	file, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	assert.Equal(t, []string{XLSXReviewsSheet, XLSXMichelinSheet, XLSXSummarySheet}, file.GetSheetList())
+
+	// Reviews, with typed cells and a frozen header
+	rows, err := file.GetRows(XLSXReviewsSheet)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, XLSXHeaders, rows[0])
+	assert.Equal(t, subRatingHeader(SubRatingAspects[0]), rows[0][xlsxSubRatingColumn-1], "the sub-rating columns follow the review columns")
+	assert.Equal(t, "Line 1\nLine 2", rows[1][3])
+	assert.Equal(t, "2025-06-15", rows[1][6])
+
+	// The date is stored as a serial number formatted as a date
+	createdDate, err := file.GetCellValue(XLSXReviewsSheet, "G2", excelize.Options{RawCellValue: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "45823", createdDate)
+
+	textStyleID, err := file.GetCellStyle(XLSXReviewsSheet, "D2")
+	assert.NoError(t, err)
+	textStyle, err := file.GetStyle(textStyleID)
+	assert.NoError(t, err)
+	assert.True(t, textStyle.Alignment.WrapText)
+
+	panes, err := file.GetPanes(XLSXReviewsSheet)
+	assert.NoError(t, err)
+	assert.True(t, panes.Freeze)
+	assert.Equal(t, 1, panes.YSplit)
+
+	// Michelin awards
+	rows, err = file.GetRows(XLSXMichelinSheet)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Award", "Title", "Year", "Description"}, {"ONE_STAR", "", "2025"}}, rows)
+
+	// Summary with the response figures and the rating distribution
+	rows, err = file.GetRows(XLSXSummarySheet)
+	assert.NoError(t, err)
+	assert.Contains(t, rows, []string{"Reviews", "3"})
+	assert.Contains(t, rows, []string{"Responses", "1"})
+	assert.Contains(t, rows, []string{"Median Response Days", "2"})
+	assert.Contains(t, rows, []string{"Partial", "TRUE"})
+	assert.Contains(t, rows, []string{"5", "2"})
+	assert.Contains(t, rows, []string{"4", "1"})
+	assert.Contains(t, rows, []string{"1", "0"})
+}
+
+func TestXLSXReviewWriterWithoutMichelin(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewXLSXReviewWriter(&buf)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel"}))
+	assert.NoError(t, writer.Close())
+
+	file, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	assert.Equal(t, []string{XLSXReviewsSheet, XLSXSummarySheet}, file.GetSheetList())
+	assert.False(t, CanResume("xlsx"))
+}
+
+func TestCutoffFromXLSXFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.xlsx")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+
+	writer := NewXLSXReviewWriter(file)
+	assert.NoError(t, writer.Begin(&ScrapeMetadata{LocationName: "Test_Hotel"}))
+	assert.NoError(t, writer.Write([]Review{
+		{CreatedDate: "2025-05-01", Title: "A", Text: "a"},
+		{CreatedDate: "2025-04-01", Title: "B", Text: "b"},
+	}))
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, file.Close())
+
+	cutoff, err := CutoffFromFile(path)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC).Equal(cutoff.Date))
+	assert.False(t, cutoff.IsNew(Review{CreatedDate: "2025-05-01", Title: "A", Text: "a"}))
+	assert.True(t, cutoff.IsNew(Review{CreatedDate: "2025-05-01", Title: "C", Text: "c"}))
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/internal/batch"
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/internal/config"
+	"github.com/algo7/TripAdvisor-Review-Scraper/scraper/pkg/tripadvisor"
+)
+
+// scraper holds what is shared by the scrapes of every location of a run
+type scraper struct {
+	client      *http.Client
+	config      *config.Config
+	limiter     *tripadvisor.RateLimiter
+	retryPolicy tripadvisor.RetryPolicy
+}
+
+// scrapeLocation scrapes the reviews of a single location.
+// In batch mode, the output and checkpoint files are named after the location so that several locations can share a directory.
+func (s *scraper) scrapeLocation(ctx context.Context, locationURL string, batchMode bool, logger *log.Logger) (batch.Result, error) {
+	var michelinInfo *tripadvisor.MichelinInfo
+	result := batch.Result{URL: locationURL}
+
+	// Parse the location from the URL, or from the location ID and the configured location type
+	location, err := tripadvisor.ParseLocation(locationURL, s.config.LocationType)
+	if err != nil {
+		return result, fmt.Errorf("error parsing URL: %w", err)
+	}
+	locationType, err := tripadvisor.LookupLocationType(location.Type)
+	if err != nil {
+		return result, fmt.Errorf("error looking up location type: %w", err)
+	}
+	logger.Printf("Location Type: %s", location.Type)
+	logger.Printf("Location ID: %d", location.LocationID)
+	logger.Printf("Location Name: %s", location.Name)
+	result.LocationID = location.LocationID
+	result.LocationName = location.Name
+
+	// The same location is reachable from every TripAdvisor domain and review page, so the checkpoint identifies it by its canonical URL
+	canonicalURL := cmp.Or(location.URL, locationURL)
+
+	// Create the client of the location, reporting retries to the logger of the location
+	clientOptions := []tripadvisor.ClientOption{
+		tripadvisor.WithHTTPClient(s.client),
+		tripadvisor.WithLogger(logger),
+		tripadvisor.WithRetryPolicy(s.retryPolicy),
+		tripadvisor.WithDebug(s.config.Debug),
+	}
+
+	// The rate limiter only applies to the concurrent mode, the sequential mode waits randomly between pages instead
+	if s.config.Concurrency > 1 {
+		clientOptions = append(clientOptions, tripadvisor.WithRateLimiter(s.limiter))
+	}
+
+	// Send the requests to the domain of the URL if asked to, e.g. to get the reviews as shown on www.tripadvisor.fr
+	if s.config.RegionalEndpoint && location.Domain != "" {
+		clientOptions = append(clientOptions, tripadvisor.WithDomain(location.Domain))
+		logger.Printf("Using the regional endpoint of %s", location.Domain)
+	}
+
+	client := tripadvisor.NewClient(clientOptions...)
+	reviewsOptions := tripadvisor.ReviewsOptions{Languages: s.config.Languages}
+
+	// Machine translated reviews are fetched a second time as written if asked to, so that both versions are kept
+	reviewsOptions.Request.DisableMachineTranslation = s.config.DisableMachineTranslation
+	reviewsOptions.OriginalText = s.config.OriginalText && !s.config.DisableMachineTranslation
+
+	// Let TripAdvisor filter and sort the reviews
+	reviewsOptions.Request.Filters = s.config.Filters
+	reviewsOptions.Request.SortBy = s.config.SortBy
+	reviewsOptions.Request.PhotosPerReview = s.config.PhotosPerReview
+	if s.config.SortBy != "" && !locationType.SortableByDate {
+		logger.Printf("%s reviews can not be sorted, so they are fetched in the default order", locationType.Label)
+	}
+
+	// Name the output and checkpoint files
+	fileName := fmt.Sprintf("reviews.%s", s.config.FileType)
+	checkpointFile := s.config.CheckpointFile
+	if batchMode {
+		// A sqlite database accumulates every location, while other outputs hold a single location
+		if s.config.FileType != "sqlite" {
+			fileName = fmt.Sprintf("reviews-%s-%d.%s", location.Name, location.LocationID, s.config.FileType)
+		}
+		ext := filepath.Ext(checkpointFile)
+		checkpointFile = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(checkpointFile, ext), location.LocationID, ext)
+	}
+
+	// In incremental mode, only the reviews newer than the cutoff are scraped.
+	// The cutoff is read before the output file is created, as the previous output may be the same file.
+	var cutoff *tripadvisor.Cutoff
+	if s.config.Since != "" {
+		cutoff, err = tripadvisor.ParseCutoffDate(s.config.Since)
+	} else if s.config.SinceFile != "" {
+		cutoff, err = tripadvisor.CutoffFromFile(s.config.SinceFile)
+	}
+	if err != nil {
+		return result, fmt.Errorf("error determining the incremental cutoff: %w", err)
+	}
+	if cutoff != nil {
+		logger.Printf("Incremental mode: scraping reviews created since %s", cutoff.Date.Format("2006-01-02"))
+		reviewsOptions.Request.SortBy = tripadvisor.SortByDate
+		if !locationType.SortableByDate {
+			logger.Printf("%s reviews can not be sorted by date, so every page is fetched and filtered", locationType.Label)
+		}
+	}
+
+	// The reviews of every language are fetched in a single pass, or in a pass per language in per-language mode
+	type languagePass struct {
+		languages  []string
+		iterations uint32
+	}
+	var passes []languagePass
+	reviewCount := 0
+
+	// The review counts only include the reviews selected by the filters
+	if s.config.PerLanguage {
+		// Fetch the review count of each language
+		counts, err := client.FilteredReviewCounts(ctx, location, reviewsOptions)
+		if err != nil {
+			return result, fmt.Errorf("error fetching review counts: %w", err)
+		}
+
+		for _, language := range s.config.Languages {
+			iterations := tripadvisor.CalculateIterations(uint32(counts[language]))
+			logger.Printf("Review count (%s): %d. Iterations: %d", language, counts[language], iterations)
+			passes = append(passes, languagePass{languages: []string{language}, iterations: iterations})
+			reviewCount += counts[language]
+		}
+		result.LanguageReviewCounts = counts
+	} else {
+		// Fetch the review count for the given location ID
+		reviewCount, err = client.CountReviews(ctx, location, reviewsOptions)
+		if err != nil {
+			return result, fmt.Errorf("error fetching review count: %w", err)
+		}
+		logger.Printf("Review count: %d", reviewCount)
+
+		// Calculate the number of iterations required to fetch all reviews
+		iterations := tripadvisor.CalculateIterations(uint32(reviewCount))
+		logger.Printf("Total Iterations: %d", iterations)
+		passes = append(passes, languagePass{languages: s.config.Languages, iterations: iterations})
+	}
+
+	// A location without reviews is an error, unless none of its reviews matches the filters
+	if reviewCount == 0 {
+		if s.config.Filters.IsZero() {
+			return result, fmt.Errorf("no reviews found for location ID %d", location.LocationID)
+		}
+		logger.Printf("No reviews match the filters")
+	}
+
+	// Resume from the checkpoint left behind by a previous run of the same scrape, if any.
+	// The rows of a resumed csv output follow the header of the previous run, so they must have the same format.
+	var csvFormat tripadvisor.CSVFormat
+	if s.config.FileType == "csv" {
+		csvFormat = s.csvFormat()
+	}
+	checkpointSettings := tripadvisor.CheckpointSettings{
+		LocationURL:               canonicalURL,
+		Languages:                 s.config.Languages,
+		FileType:                  s.config.FileType,
+		PerLanguage:               s.config.PerLanguage,
+		DisableMachineTranslation: s.config.DisableMachineTranslation,
+		OriginalText:              reviewsOptions.OriginalText,
+		Filters:                   s.config.Filters,
+		SortBy:                    reviewsOptions.Request.SortBy,
+		CSVFormat:                 csvFormat,
+	}
+	newCheckpoint := func() *tripadvisor.Checkpoint {
+		return tripadvisor.NewCheckpoint(checkpointSettings)
+	}
+	checkpoint, err := tripadvisor.LoadCheckpoint(checkpointFile)
+	if err != nil {
+		return result, fmt.Errorf("error loading checkpoint: %w", err)
+	}
+	if checkpoint == nil || !checkpoint.Matches(checkpointSettings) {
+		checkpoint = newCheckpoint()
+	}
+	if checkpoint.Started() && !tripadvisor.CanResume(s.config.FileType) {
+		logger.Printf("A %s output can not be resumed. Starting over", s.config.FileType)
+		checkpoint = newCheckpoint()
+	}
+
+	// Create the writer streaming the reviews to the output
+	var writer tripadvisor.ReviewWriter
+	var fileHandle *os.File
+	if s.config.FileType == "sqlite" {
+		// Reviews are upserted into the database, so resuming only means skipping the completed pages
+		sqliteWriter, err := tripadvisor.OpenSQLiteReviewWriter(fileName)
+		if err != nil {
+			return result, fmt.Errorf("error opening database %s: %w", fileName, err)
+		}
+		defer sqliteWriter.Discard()
+		writer = sqliteWriter
+	} else {
+		// Open the file to save the reviews data, continuing the output of the previous run when resuming
+		fileHandle, err = openOutputFile(fileName, checkpoint)
+		if err != nil {
+			logger.Printf("Error resuming %s: %v. Starting over", fileName, err)
+			checkpoint = newCheckpoint()
+			fileHandle, err = openOutputFile(fileName, checkpoint)
+		}
+		if err != nil {
+			return result, fmt.Errorf("error creating file %s: %w", fileName, err)
+		}
+		defer fileHandle.Close()
+
+		writerOptions := []tripadvisor.WriterOption{tripadvisor.WithCSVFormat(s.csvFormat())}
+		if s.config.NDJSONMetadata {
+			writerOptions = append(writerOptions, tripadvisor.WithMetadataLine())
+		}
+		writer, err = tripadvisor.NewReviewWriter(s.config.FileType, fileHandle, checkpoint, writerOptions...)
+		if err != nil {
+			return result, fmt.Errorf("error creating review writer: %w", err)
+		}
+	}
+	result.OutputFile = fileName
+
+	if checkpoint.Started() {
+		logger.Printf("Resuming from checkpoint %s: %d iterations (%d reviews) already completed", checkpointFile, checkpoint.PagesCompleted, checkpoint.ReviewsWritten)
+		michelinInfo = checkpoint.Michelin
+	}
+
+	// Download the photos of the reviews into a directory next to the output if asked to
+	var photoDownloader *tripadvisor.PhotoDownloader
+	if s.config.DownloadPhotos {
+		photoDir := strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "-photos"
+		photoDownloader, err = tripadvisor.NewPhotoDownloader(client, photoDir, s.config.PhotoSize)
+		if err != nil {
+			return result, fmt.Errorf("error creating photo downloader: %w", err)
+		}
+		defer photoDownloader.Close()
+		logger.Printf("Downloading the photos of the reviews to %s", photoDir)
+	}
+
+	// In per-language mode, the reviews are tagged with the language of the pass and a review already written for a previous language is dropped
+	var deduplicator *tripadvisor.ReviewDeduplicator
+	var requestedLanguage string
+	if s.config.PerLanguage {
+		deduplicator = tripadvisor.NewReviewDeduplicator(checkpoint.ReviewIDs...)
+	}
+
+	metadata := &tripadvisor.ScrapeMetadata{
+		LocationName: location.Name,
+		LocationID:   location.LocationID,
+		LocationType: location.Type,
+		LocationURL:  location.URL,
+		Michelin:     michelinInfo,
+		Keywords:     checkpoint.Keywords,
+	}
+	begun := false
+
+	// The sub-ratings that match no known aspect, reported once per location
+	unknownSubRatings := make(map[string]bool)
+
+	// Process a fetched page of reviews
+	handlePage := func(page tripadvisor.Page) error {
+
+		// Extract reviews using the shared helper (handles both ReviewsProxy and Locations paths)
+		reviews := tripadvisor.ExtractReviews(page.Responses)
+
+		// Sub-ratings are matched on their English labels, so the ones of another language are left out of the columns
+		for _, aspect := range tripadvisor.UnknownSubRatings(reviews) {
+			if !unknownSubRatings[aspect] {
+				unknownSubRatings[aspect] = true
+				logger.Printf("Sub-rating %q matches no known aspect and has no column of its own, only English labels are supported", aspect)
+			}
+		}
+
+		// Keep only the new reviews in incremental mode. Reviews are sorted newest first,
+		// so once a whole page is older than the cutoff, no later page can hold new ones.
+		reachedCutoff := false
+		if cutoff != nil {
+			reviews, reachedCutoff = cutoff.FilterNew(reviews)
+			reachedCutoff = reachedCutoff && locationType.SortableByDate
+		}
+
+		if deduplicator != nil {
+			tripadvisor.TagRequestedLanguage(reviews, requestedLanguage)
+			reviews = deduplicator.FilterNew(reviews)
+			for _, r := range reviews {
+				checkpoint.ReviewIDs = append(checkpoint.ReviewIDs, r.ID)
+			}
+		}
+
+		// Extract Michelin info once from the first response that contains it
+		if michelinInfo == nil {
+			michelinInfo = tripadvisor.ExtractMichelinInfo(page.Responses)
+		}
+
+		// Extract the keywords of airline reviews once, keeping them in the checkpoint for a resumed scrape
+		if checkpoint.Keywords == nil {
+			checkpoint.Keywords = tripadvisor.ExtractKeywords(page.Responses)
+			metadata.Keywords = checkpoint.Keywords
+		}
+
+		// Begin the output once the Michelin info, which determines the CSV columns, is known
+		if !begun {
+			metadata.Michelin = michelinInfo
+			if err := writer.Begin(metadata); err != nil {
+				return fmt.Errorf("error beginning output: %w", err)
+			}
+			begun = true
+		}
+
+		// Write the reviews to the file as soon as they arrive
+		if err := writer.Write(reviews); err != nil {
+			return fmt.Errorf("error writing reviews at iteration %d: %w", page.Iteration, err)
+		}
+
+		// A photo that can not be downloaded does not fail the scrape
+		if photoDownloader != nil {
+			if err := photoDownloader.Download(ctx, reviews); err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				logger.Printf("Some photos could not be downloaded at iteration %d: %v", page.Iteration, err)
+			}
+		}
+
+		// The size of the output file is what a resumed run truncates the file to. A database has no such size.
+		var outputSize int64
+		if fileHandle != nil {
+			outputSize, err = fileHandle.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return fmt.Errorf("error getting output size at iteration %d: %w", page.Iteration, err)
+			}
+		}
+
+		// Record the progress so that a failed run can be resumed from the next iteration
+		checkpoint.Responses.Add(reviews)
+		checkpoint.Record(page.Offset, len(reviews), outputSize, michelinInfo)
+		if err := checkpoint.Save(checkpointFile); err != nil {
+			return fmt.Errorf("error saving checkpoint at iteration %d: %w", page.Iteration, err)
+		}
+
+		if reachedCutoff {
+			logger.Printf("Reached reviews older than the cutoff at iteration %d", page.Iteration)
+			return tripadvisor.ErrStopFetching
+		}
+		return nil
+	}
+
+	// Fetch the pages of a pass from the last completed one
+	fetchPass := func(iterations uint32) error {
+		if s.config.Concurrency > 1 {
+			return client.FetchPages(ctx, location, checkpoint.PagesCompleted, iterations, s.config.Concurrency, reviewsOptions, handlePage)
+		}
+
+		for i := checkpoint.PagesCompleted; i < iterations; i++ {
+
+			// Introduce random delay to avoid getting blocked. The delay is between 1 and 5 seconds
+			delay := rand.Intn(5) + 1
+			logger.Printf("Iteration: %d. Delaying for %d seconds", i, delay)
+			if err := tripadvisor.Sleep(ctx, time.Duration(delay)*time.Second); err != nil {
+				return err
+			}
+
+			// Calculate the offset for the current iteration
+			offset := tripadvisor.CalculateOffset(i)
+			reviewsOptions.Offset = offset
+
+			// Make the request to the TripAdvisor GraphQL endpoint
+			resp, err := client.Reviews(ctx, location, reviewsOptions)
+			if err != nil {
+				return fmt.Errorf("error making request at iteration %d: %w", i, err)
+			}
+
+			err = handlePage(tripadvisor.Page{Iteration: i, Offset: offset, Responses: resp})
+			if errors.Is(err, tripadvisor.ErrStopFetching) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("error processing iteration %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	// Scrape the reviews
+	if s.config.Concurrency > 1 {
+		logger.Printf("Fetching with %d workers at up to %.2f requests per second", s.config.Concurrency, s.config.RequestsPerSecond)
+	}
+	var scrapeErr error
+	for checkpoint.LanguageIndex < len(passes) {
+		pass := passes[checkpoint.LanguageIndex]
+		reviewsOptions.Languages = pass.languages
+		if s.config.PerLanguage {
+			requestedLanguage = pass.languages[0]
+			logger.Printf("Scraping the %s reviews", requestedLanguage)
+		}
+
+		if scrapeErr = fetchPass(pass.iterations); scrapeErr != nil {
+			break
+		}
+
+		// Move on to the next language, recording it so that a resumed run does not fetch this one again
+		checkpoint.NextLanguage()
+		if checkpoint.LanguageIndex < len(passes) {
+			if scrapeErr = checkpoint.Save(checkpointFile); scrapeErr != nil {
+				break
+			}
+		}
+	}
+
+	// When interrupted or failed, the reviews written so far are kept as a partial output and the checkpoint is kept to resume from.
+	// The output is completed all the same, so that a json document is never left unterminated.
+	interrupted := ctx.Err() != nil
+	stopped := scrapeErr != nil || interrupted
+
+	// Complete the output
+	metadata.Partial = stopped
+	metadata.FinishedAt = time.Now()
+	var completeErr error
+	if !begun {
+		if err := writer.Begin(metadata); err != nil {
+			completeErr = fmt.Errorf("error beginning output: %w", err)
+		}
+	}
+	if completeErr == nil {
+		if err := writer.Close(); err != nil {
+			completeErr = fmt.Errorf("error completing output: %w", err)
+		}
+	}
+	if completeErr != nil {
+		if !stopped {
+			return result, completeErr
+		}
+		logger.Printf("Error completing the partial output: %v", completeErr)
+	}
+	result.ReviewCount = checkpoint.ReviewsWritten
+	result.ResponseRate = checkpoint.Responses.ResponseRate()
+	result.MedianResponseDays = checkpoint.Responses.MedianResponseDays()
+
+	// A csv file can not flag itself as partial, so a marker file named after it, such as reviews-partial for reviews.csv, does
+	partialMarker := ""
+	if s.config.FileType == "csv" {
+		partialMarker = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "-partial"
+	}
+
+	if stopped {
+		result.Partial = true
+		logger.Printf("Partial data (%d reviews) written to %s", checkpoint.ReviewsWritten, fileName)
+		if partialMarker != "" {
+			if err := writePartialMarker(partialMarker, fileName, checkpoint.ReviewsWritten, checkpointFile); err != nil {
+				logger.Printf("Error writing partial marker: %v", err)
+			}
+		}
+		if interrupted {
+			return result, fmt.Errorf("scrape interrupted: %w. Rerun with the same settings to resume from %s", ctx.Err(), checkpointFile)
+		}
+		return result, fmt.Errorf("error scraping reviews: %w. Rerun with the same settings to resume from %s", scrapeErr, checkpointFile)
+	}
+
+	logger.Printf("Data written to %s", fileName)
+
+	// The scrape is complete, so the checkpoint and the partial marker of an interrupted run are no longer needed
+	if err := tripadvisor.RemoveCheckpoint(checkpointFile); err != nil {
+		logger.Printf("Error removing checkpoint: %v", err)
+	}
+	if partialMarker != "" {
+		if err := os.Remove(partialMarker); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Printf("Error removing partial marker: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// csvFormat returns the configured format of the csv outputs
+func (s *scraper) csvFormat() tripadvisor.CSVFormat {
+	return tripadvisor.CSVFormat{
+		Columns:   s.config.CSVColumns,
+		Delimiter: s.config.CSVDelimiter,
+		QuoteAll:  s.config.CSVQuoteAll,
+		BOM:       s.config.CSVBOM,
+	}
+}
+
+// writePartialMarker writes the marker file telling that the output file holds the reviews of an interrupted or failed scrape
+func writePartialMarker(fileName string, outputFile string, reviewCount int, checkpointFile string) error {
+	content := fmt.Sprintf("%s is partial: the scrape stopped after %d reviews. Rerun with the same settings to resume from %s\n", filepath.Base(outputFile), reviewCount, checkpointFile)
+	if err := os.WriteFile(fileName, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("error writing file %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// openOutputFile opens the output file for the scrape described by the checkpoint.
+// A new scrape starts with an empty file. A resumed scrape reopens the file and drops anything written after the last completed page.
+func openOutputFile(fileName string, checkpoint *tripadvisor.Checkpoint) (*os.File, error) {
+	if !checkpoint.Started() {
+		return os.Create(fileName)
+	}
+
+	fileHandle, err := os.OpenFile(fileName, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the partial page written after the last checkpoint, if any
+	if err := fileHandle.Truncate(checkpoint.OutputSize); err != nil {
+		fileHandle.Close()
+		return nil, err
+	}
+	if _, err := fileHandle.Seek(checkpoint.OutputSize, io.SeekStart); err != nil {
+		fileHandle.Close()
+		return nil, err
+	}
+
+	return fileHandle, nil
+}